@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"os"
@@ -14,6 +15,9 @@ import (
 	_ "github.com/lib/pq" // PostgreSQL Driver
 )
 
+// importWorkers 同時處理幾個匯入工作
+const importWorkers = 4
+
 func main() {
 	// 1. 連線資料庫
 	// 請將此字串換成你的 Supabase Connection String
@@ -50,7 +54,10 @@ func main() {
 	// 2. 初始化依賴注入 (Dependency Injection)
 	repo := repository.NewPostgresRepository(db)
 	svc := service.NewReviewService(repo)
+	// 背景處理歷史紀錄匯入的 Worker Pool
+	svc.StartImportWorkers(context.Background(), importWorkers)
 	h := handler.NewReviewHandler(svc)
+	optimizerHandler := handler.NewOptimizerHandler(service.NewOptimizerService(repo, svc))
 
 	// 3. 設定 Gin Router (API Endpoints 就在這裡！)
 	r := gin.Default()
@@ -66,13 +73,70 @@ func main() {
 		// [核心功能路由]
 		// 1. 匯入歷史紀錄 (Chrome Extension 會打這支)
 		api.POST("/history", h.HandleImportHistory)
+		api.GET("/jobs/:id", h.HandleGetImportJob)
+		api.GET("/sync/cursor", h.HandleGetSyncCursor)
 
 		// 2. 獲取每日任務 (Web App 首頁會打這支)
 		api.GET("/tasks", h.HandleGetDailyTasks)
 		// (註：HandleGetDailyTasks 的程式碼在上一則對話中)
 
-		// 3. 提交練習結果 (做完題目後打這支)
+		// 3. 提交練習結果 (做完題目後打這支)，?explain=true 會附上排程的計算過程
 		api.POST("/reviews", h.HandleSubmitReview)
+
+		// 單題狀態 (含目前的記憶保留率)
+		api.GET("/stats/:question_id", h.HandleGetQuestionStats)
+		// 單題的練習紀錄 (匯入的紀錄含排程的計算過程，用於稽核)
+		api.GET("/stats/:question_id/logs", h.HandleGetQuestionLogs)
+		api.GET("/stats/:question_id/timeline", h.HandleGetQuestionTimeline)
+
+		// 題目關聯圖 (同模式 / 延伸題 / 變形題)，複習時會連動調整相關題目
+		api.GET("/questions/:question_id/related", h.HandleListRelations)
+		api.POST("/questions/:question_id/related", h.HandleAddRelation)
+		api.DELETE("/questions/:question_id/related/:related_id", h.HandleRemoveRelation)
+
+		// 未來 N 天的複習量預測 (Monte Carlo)
+		api.GET("/forecast", h.HandleGetForecast)
+
+		// 一直答錯的題目 (leech)：列出、解除暫停、重置
+		api.GET("/leeches", h.HandleListLeeches)
+		api.POST("/leeches/:question_id/unsuspend", h.HandleUnsuspendLeech)
+		api.POST("/leeches/:question_id/reset", h.HandleResetLeech)
+
+		// 4. 排程演算法設定 (每個使用者可選不同演算法)
+		api.GET("/settings/algorithm", h.HandleGetAlgorithm)
+		api.PUT("/settings/algorithm", h.HandleUpdateAlgorithm)
+		// 排程參數 (間隔上限、EF 底限、倍率、Fuzz)，?preview=true 只預覽不儲存
+		api.GET("/settings/scheduler", h.HandleGetSchedulerConfig)
+		api.PUT("/settings/scheduler", h.HandleUpdateSchedulerConfig)
+		// 新題依難度與分類的初始 EF / 第二次複習間隔
+		api.GET("/settings/initial-ease", h.HandleGetInitialEasePolicy)
+		api.PUT("/settings/initial-ease", h.HandleUpdateInitialEasePolicy)
+		// 匯入時 LeetCode 狀態對應的評分 (Compile Error 略過、TLE 算 Hard ...)
+		api.GET("/settings/status-grades", h.HandleGetStatusGradeMapping)
+		api.PUT("/settings/status-grades", h.HandleUpdateStatusGradeMapping)
+		api.GET("/settings/session-window", h.HandleGetSessionWindow)
+		api.PUT("/settings/session-window", h.HandleUpdateSessionWindow)
+		// 自動評分門檻 (依花費時間、錯誤次數、提示推導 Grade)
+		api.GET("/settings/grading", h.HandleGetGradingPolicy)
+		api.PUT("/settings/grading", h.HandleUpdateGradingPolicy)
+		// Leech 門檻與處理方式 (suspend / tag)
+		api.GET("/settings/leech", h.HandleGetLeechPolicy)
+		api.PUT("/settings/leech", h.HandleUpdateLeechPolicy)
+		// 相關題目連動的幅度
+		api.GET("/settings/related-credit", h.HandleGetRelatedCreditPolicy)
+		api.PUT("/settings/related-credit", h.HandleUpdateRelatedCreditPolicy)
+		// 面試模式 (面試前最後一週保證每題都再複習一次)
+		api.GET("/settings/deadline", h.HandleGetDeadline)
+		api.PUT("/settings/deadline", h.HandleUpdateDeadline)
+		api.DELETE("/settings/deadline", h.HandleDeleteDeadline)
+
+		// 5. 參數擬合 (依使用者的 study_logs 重新擬合改良版 SM-2 參數)
+		api.POST("/optimizer/fit", optimizerHandler.HandleFitParams)
+		api.GET("/optimizer/params", optimizerHandler.HandleGetParams)
+
+		// 6. 維運 (切換演算法或調整參數後，從 study_logs 重算所有狀態)
+		api.POST("/admin/recompute", h.HandleRecompute)
+		api.GET("/admin/recompute/:id", h.HandleGetRecomputeJob)
 	}
 
 	// 4. 啟動伺服器
@@ -0,0 +1,149 @@
+// cmd/cli 是給維運用的指令列工具
+//
+// 用法：
+//
+//	go run ./cmd/cli optimize -user <user_id>   # 重新擬合單一使用者的參數
+//	go run ./cmd/cli optimize -all              # 重新擬合所有使用者的參數
+//	go run ./cmd/cli recompute -user <user_id>  # 以目前的演算法重算單一使用者的狀態
+//	go run ./cmd/cli recompute -all -dry-run    # 列出所有使用者會改變的題目，不寫入
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"letracker/internal/repository"
+	"letracker/internal/service"
+	"letracker/pkg/optimizer"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq" // PostgreSQL Driver
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	// .env 不存在時直接使用環境變數
+	_ = godotenv.Load()
+
+	connStr := os.Getenv("DB_DSN")
+	if connStr == "" {
+		log.Fatal("DB_DSN environment variable is not set")
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		log.Fatal("Failed to connect to DB:", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "optimize":
+		err = runOptimize(ctx, repo, os.Args[2:])
+	case "recompute":
+		err = runRecompute(ctx, repo, os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cli <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  optimize   refit per-user scheduler parameters from study_logs")
+	fmt.Fprintln(os.Stderr, "  recompute  rebuild user_question_stats by replaying study_logs")
+}
+
+// runOptimize 重新擬合一位或所有使用者的參數
+func runOptimize(ctx context.Context, repo repository.Repository, args []string) error {
+	fs := flag.NewFlagSet("optimize", flag.ExitOnError)
+	userID := fs.String("user", "", "user id to refit")
+	all := fs.Bool("all", false, "refit every user with study logs")
+	fs.Parse(args)
+
+	if *userID == "" && !*all {
+		return errors.New("optimize: either -user or -all is required")
+	}
+
+	userIDs := []string{*userID}
+	if *all {
+		ids, err := repo.ListUserIDs(ctx)
+		if err != nil {
+			return err
+		}
+		userIDs = ids
+	}
+
+	svc := service.NewOptimizerService(repo, service.NewReviewService(repo))
+	for _, id := range userIDs {
+		params, err := svc.FitParams(ctx, id)
+		if err != nil {
+			if errors.Is(err, optimizer.ErrNotEnoughData) {
+				log.Printf("skip %s: %v", id, err)
+				continue
+			}
+			return fmt.Errorf("optimize %s: %w", id, err)
+		}
+		log.Printf("%s: hard=%.3f easy=%.3f retention=%.3f log-loss %.4f -> %.4f (%d reviews)",
+			id, params.HardModifier, params.EasyBonus, params.RetentionBonus,
+			params.BaselineLogLoss, params.LogLoss, params.ReviewCount)
+	}
+
+	return nil
+}
+
+// runRecompute 以目前的演算法與設定，從 study_logs 重算一位或所有使用者的狀態
+func runRecompute(ctx context.Context, repo repository.Repository, args []string) error {
+	fs := flag.NewFlagSet("recompute", flag.ExitOnError)
+	userID := fs.String("user", "", "user id to recompute")
+	all := fs.Bool("all", false, "recompute every user with study logs")
+	dryRun := fs.Bool("dry-run", false, "print the changes without writing them")
+	fs.Parse(args)
+
+	if (*userID == "") == !*all {
+		return errors.New("recompute: exactly one of -user or -all is required")
+	}
+
+	svc := service.NewReviewService(repo)
+	result, err := svc.Recompute(ctx, service.RecomputeRequest{UserID: *userID, All: *all, DryRun: *dryRun},
+		func(p service.RecomputeProgress) {
+			log.Printf("[%d/%d] %s: %d questions, %d changed", p.Done, p.Total, p.UserID, p.Questions, p.Changed)
+		})
+	if err != nil {
+		return err
+	}
+
+	for _, diff := range result.Diffs {
+		before := "(none)"
+		if diff.Before != nil {
+			before = fmt.Sprintf("%s %dd ef=%.2f next=%s", diff.Before.Status, diff.Before.IntervalDays,
+				diff.Before.EaseFactor, diff.Before.NextReviewAt.Format("2006-01-02"))
+		}
+		log.Printf("%s %s: %s -> %s %dd ef=%.2f next=%s", diff.UserID, diff.QuestionID, before,
+			diff.After.Status, diff.After.IntervalDays, diff.After.EaseFactor, diff.After.NextReviewAt.Format("2006-01-02"))
+	}
+
+	verb := "updated"
+	if *dryRun {
+		verb = "would update"
+	}
+	log.Printf("%d users, %d questions, %s %d", result.Users, result.Questions, verb, result.Changed)
+	return nil
+}
@@ -3,34 +3,42 @@ document.getElementById("syncBtn").addEventListener("click", async () => {
   statusDiv.textContent = "Fetching from LeetCode...";
 
   try {
-    // 1. 呼叫 LeetCode API (它會自動帶上你瀏覽器的 Cookie，所以不用擔心登入問題)
-    // limit=1000 應該夠抓很久的紀錄，不夠的話要寫迴圈 fetch offset
-    const response = await fetch(
-      "https://leetcode.com/api/submissions/?offset=0&limit=1000",
-    );
+    // 1. 先問 Backend 上次同步到哪一筆，只抓之後的紀錄
+    const cursor = await fetchCursor();
 
-    if (!response.ok) {
-      throw new Error("Failed to fetch from LeetCode. Are you logged in?");
+    // 2. 呼叫 LeetCode API (它會自動帶上你瀏覽器的 Cookie，所以不用擔心登入問題)
+    // 紀錄從新到舊排列，一頁一頁往回抓，碰到游標就停
+    const submissions = await fetchSubmissionsSince(cursor, (count) => {
+      statusDiv.textContent = `Fetching from LeetCode... (${count} new records)`;
+    });
+
+    if (submissions.length === 0) {
+      statusDiv.textContent = "Already up to date.";
+      statusDiv.style.color = "green";
+      return;
     }
 
-    const data = await response.json();
-    const submissions = data.submissions_dump; // LeetCode 回傳的陣列 key 叫這個
+    // 題目難度 (submissions API 沒有，另外抓題目列表建立 slug -> 難度 的對照)
+    const difficulties = await fetchDifficulties();
 
     statusDiv.textContent = `Fetched ${submissions.length} records. Sending to Backend...`;
 
-    // 2. 轉換格式以符合我們 Go Backend 的需求
+    // 3. 轉換格式以符合我們 Go Backend 的需求
     // LeetCode 格式: { title_slug: "two-sum", status_display: "Accepted", timestamp: 167... }
-    // Go Backend 格式: { slug, status, timestamp, title }
+    // Go Backend 格式: { slug, status, timestamp, title, difficulty }
     console.log(submissions);
+    // submission_id 讓 Backend 跳過已經匯入過的紀錄，重複同步不會寫入兩次
     const formattedHistory = submissions.map((sub) => ({
+      submission_id: sub.id,
       title: sub.title,
       slug: sub.title_slug,
       status: sub.status_display, // "Accepted", "Wrong Answer", "Runtime Error"
       timestamp: sub.timestamp,
+      difficulty: difficulties[sub.title_slug] || "",
     }));
 
-    // 3. 傳送給你的 Go Backend
-    const backendResp = await fetch("http://localhost:8080/api/v1/history", {
+    // 4. 傳送給你的 Go Backend
+    const backendResp = await fetch(`${BACKEND_URL}/history`, {
       method: "POST",
       headers: {
         "Content-Type": "application/json",
@@ -43,12 +51,117 @@ document.getElementById("syncBtn").addEventListener("click", async () => {
       throw new Error("Backend Error: " + errText);
     }
 
-    const result = await backendResp.json();
-    statusDiv.textContent = `Success! Imported ${result.count} records.`;
-    statusDiv.style.color = "green";
+    // 5. 匯入在 Backend 背景執行，輪詢工作進度直到完成
+    const { job_id: jobId } = await backendResp.json();
+    const job = await waitForJob(jobId, (progress) => {
+      statusDiv.textContent = `Importing... ${progress.processed_slugs}/${progress.total_slugs} problems`;
+    });
+
+    if (job.status === "failed") {
+      throw new Error("Import failed: " + job.error);
+    }
+    statusDiv.textContent = `Success! Imported ${job.imported} new records (${job.skipped} already synced, ${job.ignored} ignored).`;
+    if (job.failed_slugs > 0) {
+      statusDiv.textContent += ` ${job.failed_slugs} problems failed, they will be retried next sync.`;
+    }
+    statusDiv.style.color = job.failed_slugs > 0 ? "orange" : "green";
   } catch (err) {
     console.error(err);
     statusDiv.textContent = "Error: " + err.message;
     statusDiv.style.color = "red";
   }
 });
+
+// 題目列表的 difficulty.level: 1 = Easy, 2 = Medium, 3 = Hard
+// 抓不到也不影響同步，Backend 會把沒有難度的題目當成 Medium
+async function fetchDifficulties() {
+  const levels = { 1: "Easy", 2: "Medium", 3: "Hard" };
+  try {
+    const response = await fetch("https://leetcode.com/api/problems/all/");
+    if (!response.ok) {
+      return {};
+    }
+    const data = await response.json();
+    const difficulties = {};
+    for (const pair of data.stat_status_pairs) {
+      difficulties[pair.stat.question__title_slug] = levels[pair.difficulty.level];
+    }
+    return difficulties;
+  } catch (err) {
+    console.error(err);
+    return {};
+  }
+}
+
+const BACKEND_URL = "http://localhost:8080/api/v1";
+const PAGE_SIZE = 20;
+
+// 上次匯入到哪一筆 submission，抓不到就當作沒同步過 (Backend 會跳過重複的紀錄)
+async function fetchCursor() {
+  try {
+    const response = await fetch(`${BACKEND_URL}/sync/cursor`);
+    if (!response.ok) {
+      return { last_submission_id: 0, last_timestamp: 0 };
+    }
+    return await response.json();
+  } catch (err) {
+    console.error(err);
+    return { last_submission_id: 0, last_timestamp: 0 };
+  }
+}
+
+// 分頁抓取 cursor 之後的 submission (回傳從新到舊)
+async function fetchSubmissionsSince(cursor, onProgress) {
+  const isSynced = (sub) =>
+    cursor.last_submission_id
+      ? sub.id <= cursor.last_submission_id
+      : sub.timestamp < cursor.last_timestamp;
+
+  const submissions = [];
+  let offset = 0;
+  let lastKey = "";
+
+  while (true) {
+    const response = await fetch(
+      `https://leetcode.com/api/submissions/?offset=${offset}&limit=${PAGE_SIZE}&lastkey=${lastKey}`,
+    );
+    if (!response.ok) {
+      throw new Error("Failed to fetch from LeetCode. Are you logged in?");
+    }
+
+    const data = await response.json();
+    const page = data.submissions_dump; // LeetCode 回傳的陣列 key 叫這個
+    for (const sub of page) {
+      if (isSynced(sub)) {
+        return submissions;
+      }
+      submissions.push(sub);
+    }
+    onProgress(submissions.length);
+
+    if (!data.has_next || page.length === 0) {
+      return submissions;
+    }
+    offset += PAGE_SIZE;
+    lastKey = data.last_key || "";
+  }
+}
+
+const JOB_POLL_MS = 1000;
+
+// 輪詢匯入工作，直到 done 或 failed
+async function waitForJob(jobId, onProgress) {
+  while (true) {
+    const response = await fetch(`${BACKEND_URL}/jobs/${jobId}`);
+    if (!response.ok) {
+      throw new Error("Failed to fetch import job: " + (await response.text()));
+    }
+
+    const job = await response.json();
+    if (job.status === "done" || job.status === "failed") {
+      return job;
+    }
+    onProgress(job);
+    await new Promise((resolve) => setTimeout(resolve, JOB_POLL_MS));
+  }
+}
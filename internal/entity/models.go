@@ -23,24 +23,134 @@ type SubmissionLog struct {
 	UserID           string    `json:"user_id"`
 	QuestionID       string    `json:"question_id"`
 	Status           string    `json:"status"`        // "SOLVED", "FAILED"
-	MasteryLevel     int       `json:"mastery_level"` // 0-3
+	MasteryLevel     int       `json:"mastery_level"` // 0-3 (匯入的紀錄是這筆提交自己的評分)
 	TimeTakenSeconds int       `json:"time_taken_seconds"`
 	Notes            string    `json:"notes"`
 	Date             time.Time `json:"attempted_at"`
+	// ExternalID LeetCode 的 submission id (匯入的紀錄才有，同一使用者不會重複)
+	ExternalID int64 `json:"external_id,omitempty"`
+	// SubmissionStatus LeetCode 的原始狀態 ("Accepted", "Time Limit Exceeded" ...)，重算時依目前的對應表重新評分
+	SubmissionStatus string `json:"submission_status,omitempty"`
+
+	// Trace 這次排程的計算過程 (歷史回放會保存，方便事後稽核)
+	Trace []TraceStep `json:"trace,omitempty"`
+}
+
+// TraceStep 排程計算中的一個步驟，欄位意義見 srs.TraceStep
+type TraceStep struct {
+	Step   string  `json:"step"`
+	Input  float64 `json:"input"`
+	Factor float64 `json:"factor,omitempty"`
+	Output float64 `json:"output"`
+	Note   string  `json:"note,omitempty"`
+}
+
+// SyncCursor 對應資料庫的 sync_cursors 表
+// 記錄使用者上次匯入到哪一筆 LeetCode submission
+type SyncCursor struct {
+	UserID           string
+	LastSubmissionID int64     // 0 代表沒有 submission id
+	LastTimestamp    time.Time // 最新一筆 submission 的時間
+}
+
+// 匯入工作的狀態
+const (
+	ImportJobQueued  = "queued"
+	ImportJobRunning = "running"
+	ImportJobDone    = "done"
+	ImportJobFailed  = "failed"
+)
+
+// 背景工作的類型
+const (
+	JobKindImport    = "import"    // 歷史紀錄匯入
+	JobKindRecompute = "recompute" // 重算 user_question_stats
+)
+
+// ImportJob 對應資料庫的 import_jobs 表
+// 背景處理的歷史紀錄匯入，Slugs 記錄每題的進度與錯誤
+// 重算也使用同一個佇列 (Kind 為 recompute)，進度記在 Recompute
+type ImportJob struct {
+	ID             string             `json:"id"`
+	UserID         string             `json:"-"` // 重算所有使用者的工作為空字串
+	Kind           string             `json:"kind"`
+	Status         string             `json:"status"` // "queued", "running", "done", "failed"
+	TotalRecords   int                `json:"total_records"`
+	TotalSlugs     int                `json:"total_slugs"`
+	ProcessedSlugs int                `json:"processed_slugs"`
+	FailedSlugs    int                `json:"failed_slugs"`
+	Imported       int                `json:"imported"`        // 新寫入的紀錄
+	Skipped        int                `json:"skipped"`         // 之前已經匯入過的紀錄
+	Ignored        int                `json:"ignored"`         // 依狀態對應表略過的紀錄 (例如 Compile Error)
+	Error          string             `json:"error,omitempty"` // 整個工作失敗的原因
+	Slugs          []ImportSlugResult `json:"slugs"`
+	Recompute      *RecomputeProgress `json:"recompute,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	StartedAt      *time.Time         `json:"started_at,omitempty"`
+	FinishedAt     *time.Time         `json:"finished_at,omitempty"`
+}
+
+// RecomputeProgress 重算工作的進度與結果 (存在 import_jobs.progress)
+type RecomputeProgress struct {
+	DryRun         bool            `json:"dry_run"`
+	TotalUsers     int             `json:"total_users"`
+	ProcessedUsers int             `json:"processed_users"`
+	Questions      int             `json:"questions"`
+	Changed        int             `json:"changed"`
+	Diffs          []RecomputeDiff `json:"diffs,omitempty"` // 只有 DryRun 時記錄
+}
+
+// RecomputeDiff 單題重算前後的狀態
+type RecomputeDiff struct {
+	UserID     string             `json:"user_id"`
+	QuestionID string             `json:"question_id"`
+	Before     *UserQuestionStats `json:"before"` // 還沒有狀態時為 nil
+	After      UserQuestionStats  `json:"after"`
+}
+
+// ImportSlugResult 單題的匯入結果
+type ImportSlugResult struct {
+	Slug     string `json:"slug"`
+	Imported int    `json:"imported"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ReviewSnapshot 對應資料庫的 review_snapshots 表
+// 每次練習之後的排程狀態，用來畫 EF / 間隔 / 穩定度隨時間的變化
+type ReviewSnapshot struct {
+	UserID          string    `json:"-"`
+	QuestionID      string    `json:"question_id"`
+	ReviewedAt      time.Time `json:"reviewed_at"`
+	Grade           int       `json:"grade"` // 0-3
+	Status          string    `json:"status"`
+	Streak          int       `json:"streak"`
+	EaseFactor      float64   `json:"ease_factor"`
+	IntervalDays    int       `json:"interval_days"`
+	IntervalMinutes int       `json:"interval_minutes"`
+	Stability       float64   `json:"stability"`
+	Difficulty      float64   `json:"difficulty"`
+	NextReviewAt    time.Time `json:"next_review_at"`
 }
 
 // UserQuestionStats 對應資料庫的 user_question_stats 表
 // 這是演算法計算後的當前狀態
 type UserQuestionStats struct {
-	ID             string    `json:"id"`
-	UserID         string    `json:"user_id"`
-	QuestionID     string    `json:"question_id"`
-	Streak         int       `json:"streak"`
-	EaseFactor     float64   `json:"ease_factor"`
-	IntervalDays   int       `json:"interval_days"`
-	Status         string    `json:"status"` // "NEW", "LEARNING", "REVIEW", "MASTERED"
-	NextReviewAt   time.Time `json:"next_review_at"`
-	LastReviewedAt time.Time `json:"last_reviewed_at"`
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	QuestionID      string    `json:"question_id"`
+	Streak          int       `json:"streak"`
+	EaseFactor      float64   `json:"ease_factor"`
+	IntervalDays    int       `json:"interval_days"`
+	IntervalMinutes int       `json:"interval_minutes"` // 距離下次複習的分鐘數 (學習步驟是分鐘級的)
+	LearningStep    int       `json:"learning_step"`    // 學習步驟的位置 (LEARNING / RELEARNING 時使用)
+	Status          string    `json:"status"`           // "NEW", "LEARNING", "RELEARNING", "REVIEW", "MASTERED", "SUSPENDED"
+	Stability       float64   `json:"stability"`        // FSRS 記憶穩定度 (天)，0 代表尚未建立
+	Difficulty      float64   `json:"difficulty"`       // FSRS 題目難度 1~10
+	ReviewCount     int       `json:"review_count"`     // 累計複習次數 (不會因答錯歸零)
+	Lapses          int       `json:"lapses"`           // 複習階段答錯的次數
+	IsLeech         bool      `json:"is_leech"`         // lapse 次數超過門檻，被標記為 leech
+	NextReviewAt    time.Time `json:"next_review_at"`
+	LastReviewedAt  time.Time `json:"last_reviewed_at"`
 }
 
 type QuestionTask struct {
@@ -48,7 +158,173 @@ type QuestionTask struct {
 	Title         string    `json:"title"`
 	Slug          string    `json:"slug"`
 	Difficulty    string    `json:"difficulty"`
-	Status        string    `json:"status"` // "NEW", "REVIEW"
+	Status        string    `json:"status"` // "NEW", "LEARNING", "RELEARNING", "REVIEW"
 	NextReviewAt  time.Time `json:"next_review_at"`
 	OverdueByDays float64   `json:"overdue_by_days"` // 用來顯示「逾期多久」
+
+	// 估計記憶保留率用的狀態
+	IntervalDays    int       `json:"interval_days"`
+	IntervalMinutes int       `json:"interval_minutes"`
+	Stability       float64   `json:"stability"`
+	LastReviewedAt  time.Time `json:"last_reviewed_at"`
+	// Retrievability 估計目前還記得的機率 (0~1)
+	Retrievability float64 `json:"retrievability"`
+
+	Lapses  int  `json:"lapses"`
+	IsLeech bool `json:"is_leech"`
+}
+
+// QuestionStats 單題的 SRS 狀態 + 即時估計的記憶保留率
+type QuestionStats struct {
+	UserQuestionStats
+	Retrievability float64 `json:"retrievability"`
+}
+
+// SchedulerParams 對應資料庫的 user_scheduler_params 表
+// 由 optimizer 依使用者的 study_logs 擬合出來的改良版 SM-2 參數
+type SchedulerParams struct {
+	UserID          string    `json:"user_id"`
+	HardModifier    float64   `json:"hard_modifier"`
+	EasyBonus       float64   `json:"easy_bonus"`
+	RetentionBonus  float64   `json:"retention_bonus"`
+	LogLoss         float64   `json:"log_loss"`
+	BaselineLogLoss float64   `json:"baseline_log_loss"`
+	ReviewCount     int       `json:"review_count"`
+	FittedAt        time.Time `json:"fitted_at"`
+}
+
+// SchedulerConfig 對應資料庫的 user_scheduler_configs 表
+// 使用者手動調整的排程設定，欄位意義見 srs.SchedulerConfig
+type SchedulerConfig struct {
+	UserID            string  `json:"-"`
+	MaximumInterval   int     `json:"maximum_interval"`    // 間隔上限 (天)
+	MinimumEF         float64 `json:"minimum_ef"`          // EF 底限
+	HardModifier      float64 `json:"hard_modifier"`       // 0 = 自動 (擬合值或 0.8)
+	EasyBonus         float64 `json:"easy_bonus"`          // 0 = 自動 (擬合值或 1.1)
+	SecondIntervals   [3]int  `json:"second_intervals"`    // 第二次複習的間隔 Hard / Good / Easy (天)
+	FuzzThresholdDays int     `json:"fuzz_threshold_days"` // 間隔超過幾天才加入 Fuzz
+	FuzzRange         float64 `json:"fuzz_range"`          // Fuzz 的波動範圍 (0.05 = ±5%)
+}
+
+// InitialEasePolicy 對應資料庫的 user_initial_ease_policies 表
+// 新題的初始 EF 與第二次複習間隔，依題目難度 (Easy / Medium / Hard) 與分類決定
+type InitialEasePolicy struct {
+	UserID       string                        `json:"-"`
+	Difficulties map[string]DifficultyDefaults `json:"difficulties"` // Key 為題目難度
+	Categories   map[string]float64            `json:"categories"`   // 分類 -> EF 調整量 (例如 "Graphs": -0.1)
+}
+
+// DifficultyDefaults 單一難度的新題設定
+type DifficultyDefaults struct {
+	EaseFactor    float64 `json:"ease_factor"`    // 初始 EF
+	IntervalScale float64 `json:"interval_scale"` // 第二次複習間隔的倍率 (乘在排程設定的 Hard / Good / Easy 間隔上)
+}
+
+// GradingPolicy 對應資料庫的 user_grading_policies 表
+// 用來從「花費時間、錯誤次數、是否看提示」自動推導 0-3 的評分
+type GradingPolicy struct {
+	UserID string `json:"-"`
+	// Key 為題目難度 ("Easy", "Medium", "Hard")
+	Thresholds map[string]GradeThresholds `json:"thresholds"`
+	// AC 之前錯了幾次以上，最多只給 Hard
+	HardAfterWrongAttempts int `json:"hard_after_wrong_attempts"`
+	// 看了提示最多給幾分 (預設 1 = Hard)
+	HintsCapGrade int `json:"hints_cap_grade"`
+}
+
+// GradeThresholds 單一難度的時間門檻 (分鐘)
+type GradeThresholds struct {
+	EasyMinutes int `json:"easy_minutes"` // 在這之內解出 -> Easy
+	GoodMinutes int `json:"good_minutes"` // 在這之內解出 -> Good，超過 -> Hard
+}
+
+// GradeIgnore 匯入時略過這筆 submission (不寫 Log、不排程)
+const GradeIgnore = -1
+
+// StatusGradeMapping 對應資料庫的 user_status_grade_mappings 表
+// 匯入時 LeetCode 的 status_display ("Accepted", "Wrong Answer" ...) 對應到的評分
+type StatusGradeMapping struct {
+	UserID string `json:"-"`
+	// 0-3 或 GradeIgnore (-1)；不在表裡的狀態視為 Again (0)
+	Statuses map[string]int `json:"statuses"`
+}
+
+// 達到 leech 門檻時的處理方式
+const (
+	LeechActionSuspend = "suspend" // 暫停，不再出現在每日任務
+	LeechActionTag     = "tag"     // 只標記，留給使用者做 deep-dive
+)
+
+// LeechPolicy leech 偵測設定 (存在 user_settings 表)
+type LeechPolicy struct {
+	Threshold int    `json:"threshold"` // lapse 幾次算 leech
+	Action    string `json:"action"`    // LeechActionSuspend / LeechActionTag
+}
+
+// 題目之間的關聯類型
+const (
+	RelationSamePattern = "pattern"   // 同一種解題模式 (例如都是拓撲排序)
+	RelationFollowUp    = "follow_up" // 延伸題 (Course Schedule -> Course Schedule II)
+	RelationVariant     = "variant"   // 變形題
+)
+
+// QuestionRelation 對應資料庫的 question_relations 表 (關聯是雙向的)
+type QuestionRelation struct {
+	QuestionID string `json:"question_id"`
+	RelatedID  string `json:"related_id"`
+	Relation   string `json:"relation"` // RelationSamePattern / RelationFollowUp / RelationVariant
+}
+
+// RelatedStats 相關題目的狀態，附上與本題的關聯類型
+type RelatedStats struct {
+	UserQuestionStats
+	Relation      string
+	IsNeetcode150 bool // 面試模式只限 NeetCode 150 時用來判斷範圍
+}
+
+// RelatedAdjustment 相關題目調整後的下次複習時間
+// Grade 與 Weight 會一起存成 CardEvent，重算時才能重現這次連動
+type RelatedAdjustment struct {
+	QuestionID   string
+	NextReviewAt time.Time
+	Grade        int     // 觸發連動的那一題的評分
+	Weight       float64 // 關聯強度
+}
+
+// 題目狀態的事件類型 (練習以外會改動排程的操作)
+const (
+	CardEventRelated   = "related"   // 相關題目練習後的連動
+	CardEventUnsuspend = "unsuspend" // 解除 leech 暫停
+	CardEventReset     = "reset"     // 清除 leech 並當成新題重新學習
+)
+
+// CardEvent 對應資料庫的 card_events 表
+// study_logs 只記練習，其他會改動排程的操作記在這裡，回放時依時間順序重新套用
+type CardEvent struct {
+	UserID     string
+	QuestionID string
+	Kind       string // CardEventRelated / CardEventUnsuspend / CardEventReset
+	At         time.Time
+	Grade      int     // related：觸發連動的評分
+	Weight     float64 // related：關聯強度
+}
+
+// RelatedCreditPolicy 相關題目連動設定 (存在 user_settings 表)
+type RelatedCreditPolicy struct {
+	// 答對時，相關題目的下次複習往後延「間隔 * SuccessCredit」(0 代表關閉)
+	SuccessCredit float64 `json:"success_credit"`
+	// 答錯時，相關題目剩餘的等待時間縮短 FailurePull 的比例 (0 代表關閉，1 代表立刻到期)
+	FailurePull float64 `json:"failure_pull"`
+}
+
+// 面試模式套用的題目範圍
+const (
+	DeadlineScopeAll         = "all"          // 帳號內所有題目
+	DeadlineScopeNeetCode150 = "neetcode_150" // 只有 NeetCode 150 題單
+)
+
+// InterviewDeadline 面試模式設定 (存在 user_settings 表)
+type InterviewDeadline struct {
+	Date  time.Time `json:"date"`  // 面試日期 (當天 00:00 UTC)
+	Scope string    `json:"scope"` // DeadlineScopeAll / DeadlineScopeNeetCode150
 }
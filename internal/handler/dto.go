@@ -1,14 +1,171 @@
 // internal/handler/dto.go
 package handler
 
+import (
+	"time"
+
+	"letracker/internal/entity"
+)
+
 type SubmitReviewRequest struct {
 	QuestionID string `json:"question_id" binding:"required"`
 	// 0: Again, 1: Hard, 2: Good, 3: Easy
-	Grade int `json:"grade" binding:"min=0,max=3"`
+	// 不給的話，由下面的原始訊號自動推導
+	Grade *int `json:"grade" binding:"omitempty,min=0,max=3"`
+
+	// 原始訊號 (Grade 為空時使用)
+	TimeTakenSeconds *int  `json:"time_taken_seconds" binding:"omitempty,min=0"`
+	WrongAttempts    *int  `json:"wrong_attempts" binding:"omitempty,min=0"`
+	UsedHints        *bool `json:"used_hints"`
+	Solved           *bool `json:"solved"` // 預設 true
+}
+
+// ImportPreviewResponse POST /history?dry_run=true 的回應
+type ImportPreviewResponse struct {
+	NewQuestions int                `json:"new_questions"`
+	NewLogs      int                `json:"new_logs"`
+	Skipped      int                `json:"skipped"` // 之前已經匯入過的紀錄
+	Ignored      int                `json:"ignored"` // 依狀態對應表略過的紀錄
+	Questions    []ImportDiffResult `json:"questions"`
+}
+
+type ImportDiffResult struct {
+	Slug        string          `json:"slug"`
+	QuestionID  string          `json:"question_id,omitempty"` // 新題目沒有 ID
+	NewQuestion bool            `json:"new_question"`
+	NewLogs     int             `json:"new_logs"`
+	Current     *ImportSchedule `json:"current"` // 還沒有狀態時為 null
+	Replayed    ImportSchedule  `json:"replayed"`
+}
+
+type ImportSchedule struct {
+	Status       string  `json:"status"`
+	IntervalDays int     `json:"interval_days"`
+	EaseFactor   float64 `json:"ease_factor"`
+	NextReviewAt string  `json:"next_review_at"`
+}
+
+// SyncCursorResponse 上次同步到哪一筆 submission (都是 0 代表沒同步過)
+type SyncCursorResponse struct {
+	LastSubmissionID int64 `json:"last_submission_id"`
+	LastTimestamp    int64 `json:"last_timestamp"` // Unix timestamp
+}
+
+// RecomputeRequest user_id 與 all 擇一
+type RecomputeRequest struct {
+	UserID string `json:"user_id"`
+	All    bool   `json:"all"` // 重算所有使用者
+	DryRun bool   `json:"dry_run"`
+}
+
+// RecomputeJobResponse GET /admin/recompute/:id 的回應
+type RecomputeJobResponse struct {
+	ID             string              `json:"id"`
+	Status         string              `json:"status"` // "queued", "running", "done", "failed"
+	DryRun         bool                `json:"dry_run"`
+	TotalUsers     int                 `json:"total_users"`
+	ProcessedUsers int                 `json:"processed_users"`
+	Questions      int                 `json:"questions"`
+	Changed        int                 `json:"changed"`
+	Error          string              `json:"error,omitempty"`
+	Diffs          []RecomputeDiffItem `json:"diffs,omitempty"` // 只有 dry_run 完成後回傳
+	CreatedAt      time.Time           `json:"created_at"`
+	StartedAt      *time.Time          `json:"started_at,omitempty"`
+	FinishedAt     *time.Time          `json:"finished_at,omitempty"`
+}
+
+type RecomputeDiffItem struct {
+	UserID     string          `json:"user_id"`
+	QuestionID string          `json:"question_id"`
+	Current    *ImportSchedule `json:"current"` // 還沒有狀態時為 null
+	Recomputed ImportSchedule  `json:"recomputed"`
+}
+
+type GetTasksRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+	// "overdue" (預設) 或 "retrievability"
+	Sort string `form:"sort" binding:"omitempty,oneof=overdue retrievability"`
+	// 只顯示記憶保留率低於此值的題目，例如 0.7
+	MaxRetrievability float64 `form:"max_retrievability" binding:"omitempty,gt=0,lte=1"`
 }
 
 type SubmitReviewResponse struct {
 	NextReviewAt string `json:"next_review_at"`
 	IntervalDays int    `json:"interval_days"`
-	Message      string `json:"message"`
+	Grade        int    `json:"grade"` // 實際使用的評分 (可能是 Server 推導的)
+	// 學習步驟是分鐘級的 (例如 10 分鐘後再做一次)
+	IntervalMinutes int    `json:"interval_minutes"`
+	Message         string `json:"message"`
+	// ?explain=true 時附上排程的計算過程
+	Trace []entity.TraceStep `json:"trace,omitempty"`
+	// 連動調整了幾題相關題目
+	RelatedAdjusted int `json:"related_adjusted"`
+}
+
+type UpdateAlgorithmRequest struct {
+	// "letracker-sm2", "sm2", "leitner", "fsrs"
+	Algorithm string `json:"algorithm" binding:"required"`
+}
+
+type AddRelationRequest struct {
+	RelatedID string `json:"related_id" binding:"required"`
+	// "pattern", "follow_up", "variant"
+	Relation string `json:"relation" binding:"required"`
+}
+
+type UpdateDeadlineRequest struct {
+	Date  string `json:"date" binding:"required"` // 面試日期 "2006-01-02"
+	Scope string `json:"scope"`                   // "all" (預設) 或 "neetcode_150"
+}
+
+type UpdateSessionWindowRequest struct {
+	// 同一題前後相隔幾分鐘內的提交算同一次練習 (0 代表不合併)
+	Minutes *int `json:"minutes" binding:"required,min=0,max=720"`
+}
+
+// SchedulerPreviewResponse PUT /settings/scheduler?preview=true 的回應
+type SchedulerPreviewResponse struct {
+	Config   entity.SchedulerConfig `json:"config"`
+	Sample   PreviewSample          `json:"sample"`
+	Outcomes []PreviewOutcome       `json:"outcomes"`
+}
+
+type PreviewSample struct {
+	IntervalDays int     `json:"interval_days"`
+	EaseFactor   float64 `json:"ease_factor"`
+	Streak       int     `json:"streak"`
+}
+
+type PreviewOutcome struct {
+	Grade    int             `json:"grade"`
+	Current  PreviewSchedule `json:"current"`
+	Proposed PreviewSchedule `json:"proposed"`
+}
+
+type PreviewSchedule struct {
+	NextReviewAt string  `json:"next_review_at"`
+	IntervalDays int     `json:"interval_days"`
+	EaseFactor   float64 `json:"ease_factor"`
+}
+
+type ForecastRequest struct {
+	// 模擬天數 (預設 30，常用 30 / 90 / 180)
+	Days      int `form:"days" binding:"omitempty,min=1,max=365"`
+	NewPerDay int `form:"new_per_day" binding:"omitempty,min=0,max=100"`
+	Runs      int `form:"runs" binding:"omitempty,min=1,max=2000"`
+}
+
+type ForecastDay struct {
+	Date            string  `json:"date"`
+	ExpectedReviews float64 `json:"expected_reviews"`
+	ExpectedMinutes float64 `json:"expected_minutes"`
+}
+
+type ForecastResponse struct {
+	Days                 []ForecastDay `json:"days"`
+	TotalReviews         float64       `json:"total_reviews"`
+	TotalMinutes         float64       `json:"total_minutes"`
+	AverageReviewsPerDay float64       `json:"average_reviews_per_day"`
+	PeakReviews          float64       `json:"peak_reviews"`
+	PeakDate             string        `json:"peak_date,omitempty"`
 }
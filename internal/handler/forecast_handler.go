@@ -0,0 +1,56 @@
+// internal/handler/forecast_handler.go
+package handler
+
+import (
+	"net/http"
+
+	"letracker/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleGetForecast 處理 GET /api/v1/forecast?days=90&new_per_day=5
+// 模擬未來 N 天每天的預期複習量與花費時間
+func (h *ReviewHandler) HandleGetForecast(c *gin.Context) {
+	var req ForecastRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Days == 0 {
+		req.Days = 30
+	}
+
+	// 假裝取得 UserID (之後接 Auth Middleware)
+	userID := currentUserID(c)
+
+	forecast, err := h.svc.Forecast(c.Request.Context(), userID, service.ForecastRequest{
+		Days:      req.Days,
+		NewPerDay: req.NewPerDay,
+		Runs:      req.Runs,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build forecast"})
+		return
+	}
+
+	resp := ForecastResponse{
+		Days:                 make([]ForecastDay, 0, len(forecast.Days)),
+		TotalReviews:         forecast.TotalReviews,
+		TotalMinutes:         forecast.TotalSeconds / 60,
+		AverageReviewsPerDay: forecast.TotalReviews / float64(req.Days),
+		PeakReviews:          forecast.PeakReviews,
+	}
+	if !forecast.PeakDate.IsZero() {
+		resp.PeakDate = forecast.PeakDate.Format("2006-01-02")
+	}
+	for _, d := range forecast.Days {
+		resp.Days = append(resp.Days, ForecastDay{
+			Date:            d.Date.Format("2006-01-02"),
+			ExpectedReviews: d.ExpectedReviews,
+			ExpectedMinutes: d.ExpectedSeconds / 60,
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
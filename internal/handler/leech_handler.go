@@ -0,0 +1,98 @@
+// internal/handler/leech_handler.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"letracker/internal/entity"
+	"letracker/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleListLeeches 處理 GET /api/v1/leeches
+func (h *ReviewHandler) HandleListLeeches(c *gin.Context) {
+	// 假裝取得 UserID (之後接 Auth Middleware)
+	userID := currentUserID(c)
+
+	leeches, err := h.svc.ListLeeches(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leeches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(leeches),
+		"data":  leeches,
+	})
+}
+
+// HandleUnsuspendLeech 處理 POST /api/v1/leeches/:question_id/unsuspend
+func (h *ReviewHandler) HandleUnsuspendLeech(c *gin.Context) {
+	userID := currentUserID(c)
+
+	stats, err := h.svc.UnsuspendLeech(c.Request.Context(), userID, c.Param("question_id"))
+	if err != nil {
+		respondLeechError(c, err, "Failed to unsuspend question")
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// HandleResetLeech 處理 POST /api/v1/leeches/:question_id/reset
+func (h *ReviewHandler) HandleResetLeech(c *gin.Context) {
+	userID := currentUserID(c)
+
+	stats, err := h.svc.ResetLeech(c.Request.Context(), userID, c.Param("question_id"))
+	if err != nil {
+		respondLeechError(c, err, "Failed to reset question")
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// HandleGetLeechPolicy 處理 GET /api/v1/settings/leech
+func (h *ReviewHandler) HandleGetLeechPolicy(c *gin.Context) {
+	userID := currentUserID(c)
+
+	policy, err := h.svc.GetLeechPolicy(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leech policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// HandleUpdateLeechPolicy 處理 PUT /api/v1/settings/leech
+func (h *ReviewHandler) HandleUpdateLeechPolicy(c *gin.Context) {
+	var policy entity.LeechPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+
+	if err := h.svc.SetLeechPolicy(c.Request.Context(), userID, policy); err != nil {
+		if errors.Is(err, service.ErrInvalidLeechPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update leech policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func respondLeechError(c *gin.Context, err error, message string) {
+	if errors.Is(err, service.ErrStatsNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
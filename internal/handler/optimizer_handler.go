@@ -0,0 +1,57 @@
+// internal/handler/optimizer_handler.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"letracker/internal/service"
+	"letracker/pkg/optimizer"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OptimizerHandler struct {
+	svc service.OptimizerService
+}
+
+// 建構子注入 Service
+func NewOptimizerHandler(svc service.OptimizerService) *OptimizerHandler {
+	return &OptimizerHandler{svc: svc}
+}
+
+// HandleFitParams 處理 POST /api/v1/optimizer/fit
+// 依照目前的 study_logs 重新擬合參數
+func (h *OptimizerHandler) HandleFitParams(c *gin.Context) {
+	// 假裝取得 UserID (之後接 Auth Middleware)
+	userID := currentUserID(c)
+
+	params, err := h.svc.FitParams(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, optimizer.ErrNotEnoughData) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fit parameters: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, params)
+}
+
+// HandleGetParams 處理 GET /api/v1/optimizer/params
+func (h *OptimizerHandler) HandleGetParams(c *gin.Context) {
+	userID := currentUserID(c)
+
+	params, err := h.svc.GetParams(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch parameters"})
+		return
+	}
+	if params == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Parameters have not been fitted yet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, params)
+}
@@ -0,0 +1,97 @@
+// internal/handler/relation_handler.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"letracker/internal/entity"
+	"letracker/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleListRelations 處理 GET /api/v1/questions/:question_id/related
+func (h *ReviewHandler) HandleListRelations(c *gin.Context) {
+	relations, err := h.svc.ListRelations(c.Request.Context(), c.Param("question_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch related questions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(relations),
+		"data":  relations,
+	})
+}
+
+// HandleAddRelation 處理 POST /api/v1/questions/:question_id/related
+func (h *ReviewHandler) HandleAddRelation(c *gin.Context) {
+	var req AddRelationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rel := entity.QuestionRelation{
+		QuestionID: c.Param("question_id"),
+		RelatedID:  req.RelatedID,
+		Relation:   req.Relation,
+	}
+	if err := h.svc.AddRelation(c.Request.Context(), rel); err != nil {
+		if errors.Is(err, service.ErrInvalidRelation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add related question"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rel)
+}
+
+// HandleRemoveRelation 處理 DELETE /api/v1/questions/:question_id/related/:related_id
+func (h *ReviewHandler) HandleRemoveRelation(c *gin.Context) {
+	if err := h.svc.RemoveRelation(c.Request.Context(), c.Param("question_id"), c.Param("related_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove related question"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Relation removed"})
+}
+
+// HandleGetRelatedCreditPolicy 處理 GET /api/v1/settings/related-credit
+func (h *ReviewHandler) HandleGetRelatedCreditPolicy(c *gin.Context) {
+	// 假裝取得 UserID (之後接 Auth Middleware)
+	userID := currentUserID(c)
+
+	policy, err := h.svc.GetRelatedCreditPolicy(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch related credit policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// HandleUpdateRelatedCreditPolicy 處理 PUT /api/v1/settings/related-credit
+func (h *ReviewHandler) HandleUpdateRelatedCreditPolicy(c *gin.Context) {
+	var policy entity.RelatedCreditPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+
+	if err := h.svc.SetRelatedCreditPolicy(c.Request.Context(), userID, policy); err != nil {
+		if errors.Is(err, service.ErrInvalidRelatedCreditPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update related credit policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
@@ -2,6 +2,8 @@
 package handler
 
 import (
+	"errors"
+	"letracker/internal/entity"
 	"letracker/internal/service"
 	"net/http"
 
@@ -17,6 +19,12 @@ func NewReviewHandler(svc service.ReviewService) *ReviewHandler {
 	return &ReviewHandler{svc: svc}
 }
 
+// currentUserID 取得目前的使用者 (之後接 Auth Middleware 改成 c.MustGet("userID"))
+// 所有 Handler 都從這裡拿，即時評分、匯入與設定才會讀寫同一位使用者
+func currentUserID(c *gin.Context) string {
+	return "00000000-0000-0000-0000-000000000000"
+}
+
 // HandleSubmitReview 處理 POST /api/v1/reviews
 func (h *ReviewHandler) HandleSubmitReview(c *gin.Context) {
 	var req SubmitReviewRequest
@@ -28,29 +36,64 @@ func (h *ReviewHandler) HandleSubmitReview(c *gin.Context) {
 	}
 
 	// 2. 從 Middleware 獲取 User ID (假設你有做 JWT Auth)
-	// userID := c.MustGet("userID").(string)
-	userID := "test-user-id" // 暫時寫死方便測試
+	userID := currentUserID(c)
 
 	// 3. 呼叫 Service
 	serviceReq := service.ReviewRequest{
 		QuestionID: req.QuestionID,
 		Grade:      req.Grade,
+		Signals:    req.signals(),
+		Explain:    c.Query("explain") == "true",
 	}
 
 	result, err := h.svc.ProcessReview(c.Request.Context(), userID, serviceReq)
 	if err != nil {
+		if errors.Is(err, service.ErrGradeRequired) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrQuestionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process review"})
 		return
 	}
 
 	// 4. 回傳結果
 	c.JSON(http.StatusOK, SubmitReviewResponse{
-		NextReviewAt: result.NextReviewAt.Format("2006-01-02 15:04:05"),
-		IntervalDays: result.Interval,
-		Message:      "Review recorded successfully. Keep it up!",
+		NextReviewAt:    result.NextReviewAt.Format("2006-01-02 15:04:05"),
+		IntervalDays:    result.Interval,
+		IntervalMinutes: result.IntervalMinutes,
+		Grade:           result.Grade,
+		Message:         "Review recorded successfully. Keep it up!",
+		Trace:           result.Explanation,
+		RelatedAdjusted: result.RelatedAdjusted,
 	})
 }
 
+// signals 把原始訊號轉成 Service 的格式，完全沒給就回傳 nil
+func (req SubmitReviewRequest) signals() *service.GradingSignals {
+	if req.TimeTakenSeconds == nil && req.WrongAttempts == nil && req.UsedHints == nil && req.Solved == nil {
+		return nil
+	}
+
+	sig := service.GradingSignals{Solved: true}
+	if req.TimeTakenSeconds != nil {
+		sig.TimeTakenSeconds = *req.TimeTakenSeconds
+	}
+	if req.WrongAttempts != nil {
+		sig.WrongAttempts = *req.WrongAttempts
+	}
+	if req.UsedHints != nil {
+		sig.UsedHints = *req.UsedHints
+	}
+	if req.Solved != nil {
+		sig.Solved = *req.Solved
+	}
+	return &sig
+}
+
 func (h *ReviewHandler) HandleImportHistory(c *gin.Context) {
 	var req service.ImportSubmissionRequest
 
@@ -61,27 +104,102 @@ func (h *ReviewHandler) HandleImportHistory(c *gin.Context) {
 	}
 
 	// 2. 假裝取得 UserID (之後接 Auth Middleware)
-	userID := "00000000-0000-0000-0000-000000000000"
+	userID := currentUserID(c)
 
-	// 3. 呼叫 Service
+	// ?dry_run=true：只回放、不寫入，回傳每題狀態的差異
+	if c.Query("dry_run") == "true" {
+		preview, err := h.svc.PreviewImport(c.Request.Context(), userID, req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Preview failed: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, toImportPreviewResponse(preview))
+		return
+	}
 
-	if err := h.svc.ImportHistory(c.Request.Context(), userID, req); err != nil {
+	// 3. 呼叫 Service：建立背景工作後立即回傳，進度用 GET /jobs/:id 查詢
+	job, err := h.svc.SubmitImport(c.Request.Context(), userID, req)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Import failed: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "History imported successfully",
-		"count":   len(req.History),
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Import queued",
+		"job_id":  job.ID,
+		"status":  job.Status,
+		"count":   job.TotalRecords,
 	})
 }
 
+func toImportPreviewResponse(preview *service.ImportPreview) ImportPreviewResponse {
+	resp := ImportPreviewResponse{
+		NewQuestions: preview.NewQuestions,
+		NewLogs:      preview.NewLogs,
+		Skipped:      preview.Skipped,
+		Ignored:      preview.Ignored,
+		Questions:    []ImportDiffResult{},
+	}
+	for _, q := range preview.Questions {
+		diff := ImportDiffResult{
+			Slug:        q.Slug,
+			QuestionID:  q.QuestionID,
+			NewQuestion: q.NewQuestion,
+			NewLogs:     q.NewLogs,
+			Replayed:    toImportSchedule(q.After),
+		}
+		if q.Before != nil {
+			current := toImportSchedule(*q.Before)
+			diff.Current = &current
+		}
+		resp.Questions = append(resp.Questions, diff)
+	}
+	return resp
+}
+
+func toImportSchedule(stats entity.UserQuestionStats) ImportSchedule {
+	return ImportSchedule{
+		Status:       stats.Status,
+		IntervalDays: stats.IntervalDays,
+		EaseFactor:   stats.EaseFactor,
+		NextReviewAt: stats.NextReviewAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// HandleGetImportJob 處理 GET /api/v1/jobs/:id
+// 回傳匯入工作的狀態、計數與每題的進度 (含失敗原因)
+func (h *ReviewHandler) HandleGetImportJob(c *gin.Context) {
+	userID := currentUserID(c)
+
+	job, err := h.svc.GetImportJob(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrImportJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch import job"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
 func (h *ReviewHandler) HandleGetDailyTasks(c *gin.Context) {
 	// 假設從 Middleware 拿到 UserID
-	// userID := c.MustGet("userID").(string)
-	userID := "test-user-id"
+	userID := currentUserID(c)
 
-	tasks, err := h.svc.GetTodayTasks(c.Request.Context(), userID)
+	// 可選的查詢參數：?sort=retrievability&max_retrievability=0.7&limit=10
+	var req GetTasksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tasks, err := h.svc.GetTodayTasks(c.Request.Context(), userID, service.TaskQuery{
+		Limit:             req.Limit,
+		SortBy:            req.Sort,
+		MaxRetrievability: req.MaxRetrievability,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
 		return
@@ -92,3 +210,147 @@ func (h *ReviewHandler) HandleGetDailyTasks(c *gin.Context) {
 		"tasks": tasks,
 	})
 }
+
+// HandleGetQuestionStats 處理 GET /api/v1/stats/:question_id
+// 回傳單題的 SRS 狀態與目前的記憶保留率
+func (h *ReviewHandler) HandleGetQuestionStats(c *gin.Context) {
+	userID := currentUserID(c)
+
+	stats, err := h.svc.GetQuestionStats(c.Request.Context(), userID, c.Param("question_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stats"})
+		return
+	}
+	if stats == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No stats for this question yet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// HandleGetQuestionLogs 處理 GET /api/v1/stats/:question_id/logs
+// 回傳單題的練習紀錄，匯入的紀錄附有當時排程的計算過程
+func (h *ReviewHandler) HandleGetQuestionLogs(c *gin.Context) {
+	userID := currentUserID(c)
+
+	logs, err := h.svc.GetQuestionLogs(c.Request.Context(), userID, c.Param("question_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch logs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(logs),
+		"data":  logs,
+	})
+}
+
+// HandleGetQuestionTimeline 處理 GET /api/v1/stats/:question_id/timeline
+// 回傳單題每次練習後的排程狀態 (EF、間隔、穩定度)，給圖表使用
+func (h *ReviewHandler) HandleGetQuestionTimeline(c *gin.Context) {
+	userID := currentUserID(c)
+
+	timeline, err := h.svc.GetQuestionTimeline(c.Request.Context(), userID, c.Param("question_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(timeline),
+		"data":  timeline,
+	})
+}
+
+// HandleGetSyncCursor 處理 GET /api/v1/sync/cursor
+// Extension 只需要抓游標之後的 submission，再送到 POST /history
+func (h *ReviewHandler) HandleGetSyncCursor(c *gin.Context) {
+	userID := currentUserID(c)
+
+	cursor, err := h.svc.GetSyncCursor(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sync cursor"})
+		return
+	}
+
+	resp := SyncCursorResponse{LastSubmissionID: cursor.LastSubmissionID}
+	if !cursor.LastTimestamp.IsZero() {
+		resp.LastTimestamp = cursor.LastTimestamp.Unix()
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// HandleRecompute 處理 POST /api/v1/admin/recompute
+// 以目前的演算法從 study_logs 重算狀態；建立背景工作後立即回傳，進度用 GET /admin/recompute/:id 查詢
+func (h *ReviewHandler) HandleRecompute(c *gin.Context) {
+	var req RecomputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.svc.SubmitRecompute(c.Request.Context(), service.RecomputeRequest{
+		UserID: req.UserID,
+		All:    req.All,
+		DryRun: req.DryRun,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRecomputeScope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue recompute: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Recompute queued",
+		"job_id":  job.ID,
+		"status":  job.Status,
+	})
+}
+
+// HandleGetRecomputeJob 處理 GET /api/v1/admin/recompute/:id
+// 回傳重算的進度；dry_run 的工作完成後附上會改變的題目
+func (h *ReviewHandler) HandleGetRecomputeJob(c *gin.Context) {
+	job, err := h.svc.GetRecomputeJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrImportJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recompute job"})
+		return
+	}
+
+	resp := RecomputeJobResponse{
+		ID:         job.ID,
+		Status:     job.Status,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if p := job.Recompute; p != nil {
+		resp.DryRun = p.DryRun
+		resp.TotalUsers = p.TotalUsers
+		resp.ProcessedUsers = p.ProcessedUsers
+		resp.Questions = p.Questions
+		resp.Changed = p.Changed
+		for _, diff := range p.Diffs {
+			item := RecomputeDiffItem{
+				UserID:     diff.UserID,
+				QuestionID: diff.QuestionID,
+				Recomputed: toImportSchedule(diff.After),
+			}
+			if diff.Before != nil {
+				current := toImportSchedule(*diff.Before)
+				item.Current = &current
+			}
+			resp.Diffs = append(resp.Diffs, item)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
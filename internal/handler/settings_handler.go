@@ -0,0 +1,351 @@
+// internal/handler/settings_handler.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"letracker/internal/entity"
+	"letracker/internal/service"
+	"letracker/pkg/srs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleGetAlgorithm 處理 GET /api/v1/settings/algorithm
+func (h *ReviewHandler) HandleGetAlgorithm(c *gin.Context) {
+	// 假裝取得 UserID (之後接 Auth Middleware)
+	userID := currentUserID(c)
+
+	algorithm, err := h.svc.GetAlgorithm(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch algorithm"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"algorithm": algorithm,
+		"available": srs.Algorithms(),
+	})
+}
+
+// HandleUpdateAlgorithm 處理 PUT /api/v1/settings/algorithm
+func (h *ReviewHandler) HandleUpdateAlgorithm(c *gin.Context) {
+	var req UpdateAlgorithmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+
+	if err := h.svc.SetAlgorithm(c.Request.Context(), userID, req.Algorithm); err != nil {
+		if errors.Is(err, srs.ErrUnknownAlgorithm) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update algorithm"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"algorithm": req.Algorithm,
+		"message":   "Algorithm updated",
+	})
+}
+
+// HandleGetGradingPolicy 處理 GET /api/v1/settings/grading
+func (h *ReviewHandler) HandleGetGradingPolicy(c *gin.Context) {
+	userID := currentUserID(c)
+
+	policy, err := h.svc.GetGradingPolicy(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch grading policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// HandleUpdateGradingPolicy 處理 PUT /api/v1/settings/grading
+func (h *ReviewHandler) HandleUpdateGradingPolicy(c *gin.Context) {
+	var policy entity.GradingPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+
+	saved, err := h.svc.SetGradingPolicy(c.Request.Context(), userID, policy)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidGradingPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update grading policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// HandleGetDeadline 處理 GET /api/v1/settings/deadline
+func (h *ReviewHandler) HandleGetDeadline(c *gin.Context) {
+	userID := currentUserID(c)
+
+	deadline, err := h.svc.GetInterviewDeadline(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch interview deadline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deadlineResponse(deadline))
+}
+
+// HandleUpdateDeadline 處理 PUT /api/v1/settings/deadline
+func (h *ReviewHandler) HandleUpdateDeadline(c *gin.Context) {
+	var req UpdateDeadlineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be in YYYY-MM-DD format"})
+		return
+	}
+
+	userID := currentUserID(c)
+
+	deadline, err := h.svc.SetInterviewDeadline(c.Request.Context(), userID, entity.InterviewDeadline{
+		Date:  date,
+		Scope: req.Scope,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDeadline) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update interview deadline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deadlineResponse(deadline))
+}
+
+// HandleDeleteDeadline 處理 DELETE /api/v1/settings/deadline
+func (h *ReviewHandler) HandleDeleteDeadline(c *gin.Context) {
+	userID := currentUserID(c)
+
+	if err := h.svc.ClearInterviewDeadline(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear interview deadline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deadlineResponse(nil))
+}
+
+func deadlineResponse(deadline *entity.InterviewDeadline) gin.H {
+	if deadline == nil {
+		return gin.H{"enabled": false}
+	}
+	return gin.H{
+		"enabled": true,
+		"date":    deadline.Date.Format("2006-01-02"),
+		"scope":   deadline.Scope,
+	}
+}
+
+// HandleGetSchedulerConfig 處理 GET /api/v1/settings/scheduler
+func (h *ReviewHandler) HandleGetSchedulerConfig(c *gin.Context) {
+	userID := currentUserID(c)
+
+	config, err := h.svc.GetSchedulerConfig(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduler config"})
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// HandleUpdateSchedulerConfig 處理 PUT /api/v1/settings/scheduler
+// ?preview=true 時不儲存，只回傳範例題目在新設定下的排程 (可加 &question_id= 以該題為範例)
+func (h *ReviewHandler) HandleUpdateSchedulerConfig(c *gin.Context) {
+	var config entity.SchedulerConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+
+	if c.Query("preview") == "true" {
+		preview, err := h.svc.PreviewSchedulerConfig(c.Request.Context(), userID, config, c.Query("question_id"))
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrInvalidSchedulerConfig):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, service.ErrStatsNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to preview scheduler config"})
+			}
+			return
+		}
+		c.JSON(http.StatusOK, toPreviewResponse(preview))
+		return
+	}
+
+	saved, err := h.svc.SetSchedulerConfig(c.Request.Context(), userID, config)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSchedulerConfig) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update scheduler config"})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+func toPreviewResponse(preview *service.SchedulerPreview) SchedulerPreviewResponse {
+	resp := SchedulerPreviewResponse{
+		Config: preview.Config,
+		Sample: PreviewSample{
+			IntervalDays: preview.Sample.IntervalDays,
+			EaseFactor:   preview.Sample.EaseFactor,
+			Streak:       preview.Sample.Streak,
+		},
+	}
+	for _, o := range preview.Outcomes {
+		resp.Outcomes = append(resp.Outcomes, PreviewOutcome{
+			Grade:    o.Grade,
+			Current:  toPreviewSchedule(o.Current),
+			Proposed: toPreviewSchedule(o.Proposed),
+		})
+	}
+	return resp
+}
+
+func toPreviewSchedule(out srs.ReviewOutput) PreviewSchedule {
+	return PreviewSchedule{
+		NextReviewAt: out.NextReviewAt.Format("2006-01-02 15:04:05"),
+		IntervalDays: out.Interval,
+		EaseFactor:   out.EaseFactor,
+	}
+}
+
+// HandleGetInitialEasePolicy 處理 GET /api/v1/settings/initial-ease
+func (h *ReviewHandler) HandleGetInitialEasePolicy(c *gin.Context) {
+	userID := currentUserID(c)
+
+	policy, err := h.svc.GetInitialEasePolicy(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch initial ease policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// HandleUpdateInitialEasePolicy 處理 PUT /api/v1/settings/initial-ease
+// 只需要給想覆寫的難度/分類，其餘沿用預設值
+func (h *ReviewHandler) HandleUpdateInitialEasePolicy(c *gin.Context) {
+	var overrides entity.InitialEasePolicy
+	if err := c.ShouldBindJSON(&overrides); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+
+	policy, err := h.svc.SetInitialEasePolicy(c.Request.Context(), userID, overrides)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInitialEasePolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update initial ease policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// HandleGetStatusGradeMapping 處理 GET /api/v1/settings/status-grades
+func (h *ReviewHandler) HandleGetStatusGradeMapping(c *gin.Context) {
+	userID := currentUserID(c)
+
+	mapping, err := h.svc.GetStatusGradeMapping(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status grade mapping"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mapping)
+}
+
+// HandleUpdateStatusGradeMapping 處理 PUT /api/v1/settings/status-grades
+// 只需要給想覆寫的狀態，-1 代表匯入時略過
+func (h *ReviewHandler) HandleUpdateStatusGradeMapping(c *gin.Context) {
+	var overrides entity.StatusGradeMapping
+	if err := c.ShouldBindJSON(&overrides); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+
+	mapping, err := h.svc.SetStatusGradeMapping(c.Request.Context(), userID, overrides)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatusGradeMapping) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status grade mapping"})
+		return
+	}
+
+	c.JSON(http.StatusOK, mapping)
+}
+
+// HandleGetSessionWindow 處理 GET /api/v1/settings/session-window
+func (h *ReviewHandler) HandleGetSessionWindow(c *gin.Context) {
+	userID := currentUserID(c)
+
+	minutes, err := h.svc.GetSessionWindow(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch session window"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"minutes": minutes})
+}
+
+// HandleUpdateSessionWindow 處理 PUT /api/v1/settings/session-window
+// 0 代表不合併，每次提交各自排程
+func (h *ReviewHandler) HandleUpdateSessionWindow(c *gin.Context) {
+	var req UpdateSessionWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := currentUserID(c)
+
+	if err := h.svc.SetSessionWindow(c.Request.Context(), userID, *req.Minutes); err != nil {
+		if errors.Is(err, service.ErrInvalidSessionWindow) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session window"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"minutes": *req.Minutes})
+}
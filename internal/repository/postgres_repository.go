@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"database/sql/driver"
+	"encoding/json"
 	"letracker/internal/entity"
 	"log"
+	"time"
 )
 
 type postgresRepository struct {
@@ -22,13 +24,33 @@ func NewPostgresRepository(db *sql.DB) Repository {
 // -------------------------------------------------------
 
 func (r *postgresRepository) GetQuestionBySlug(ctx context.Context, slug string) (*entity.Question, error) {
-	query := `SELECT id, title, slug FROM questions WHERE slug = $1`
+	query := `
+		SELECT id, title, slug, COALESCE(difficulty, ''), COALESCE(category, ''), COALESCE(is_neetcode_150, FALSE)
+		FROM questions WHERE slug = $1
+	`
+
+	var q entity.Question
+	err := r.db.QueryRowContext(ctx, query, slug).Scan(&q.ID, &q.Title, &q.Slug, &q.Difficulty, &q.Category, &q.IsNeetcode150)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrQuestionNotFound
+		}
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (r *postgresRepository) GetQuestionByID(ctx context.Context, id string) (*entity.Question, error) {
+	query := `
+		SELECT id, title, slug, COALESCE(difficulty, ''), COALESCE(category, ''), COALESCE(is_neetcode_150, FALSE)
+		FROM questions WHERE id = $1
+	`
 
 	var q entity.Question
-	err := r.db.QueryRowContext(ctx, query, slug).Scan(&q.ID, &q.Title, &q.Slug)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&q.ID, &q.Title, &q.Slug, &q.Difficulty, &q.Category, &q.IsNeetcode150)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("question not found")
+			return nil, ErrQuestionNotFound
 		}
 		return nil, err
 	}
@@ -38,31 +60,38 @@ func (r *postgresRepository) GetQuestionBySlug(ctx context.Context, slug string)
 func (r *postgresRepository) CreateQuestion(ctx context.Context, q entity.Question) (string, error) {
 	// 這裡使用 RETURNING id 讓 Postgres 回傳生成的 UUID
 	query := `
-		INSERT INTO questions (title, slug, is_neetcode_150)
-		VALUES ($1, $2, $3)
+		INSERT INTO questions (title, slug, difficulty, category, is_neetcode_150)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5)
 		RETURNING id
 	`
 	var id string
-	err := r.db.QueryRowContext(ctx, query, q.Title, q.Slug, q.IsNeetcode150).Scan(&id)
+	err := r.db.QueryRowContext(ctx, query, q.Title, q.Slug, q.Difficulty, q.Category, q.IsNeetcode150).Scan(&id)
 	log.Println(err)
 	return id, err
 }
 
+func (r *postgresRepository) UpdateQuestionMeta(ctx context.Context, id, difficulty, category string) error {
+	query := `
+		UPDATE questions SET
+			difficulty = COALESCE(NULLIF($2, ''), difficulty),
+			category = COALESCE(NULLIF($3, ''), category)
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, difficulty, category)
+	return err
+}
+
 // -------------------------------------------------------
 // Stats 實作
 // -------------------------------------------------------
 
 func (r *postgresRepository) GetUserStats(ctx context.Context, userID, questionID string) (*entity.UserQuestionStats, error) {
 	query := `
-		SELECT id, streak, ease_factor, interval_days, next_review_at
+		SELECT ` + statsColumns + `
 		FROM user_question_stats
 		WHERE user_id = $1 AND question_id = $2
 	`
-	var stats entity.UserQuestionStats
-	// 記得掃描進去時要小心 NULL 值，這裡假設 DB 欄位都有 NOT NULL 或 Default
-	err := r.db.QueryRowContext(ctx, query, userID, questionID).Scan(
-		&stats.ID, &stats.Streak, &stats.EaseFactor, &stats.IntervalDays, &stats.NextReviewAt,
-	)
+	stats, err := scanStats(r.db.QueryRowContext(ctx, query, userID, questionID))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -74,38 +103,274 @@ func (r *postgresRepository) GetUserStats(ctx context.Context, userID, questionI
 	return &stats, nil
 }
 
+// statsColumns UserQuestionStats 共用的 SELECT 欄位 (順序需與 scanStats 一致)
+const statsColumns = `
+	id, user_id, question_id, streak, ease_factor, interval_days, interval_minutes, learning_step, status,
+	next_review_at, last_reviewed_at, stability, difficulty, review_count, lapses, is_leech
+`
+
+// scanner 讓 *sql.Row 與 *sql.Rows 共用掃描邏輯
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// extra 為 statsColumns 之後額外 SELECT 的欄位
+func scanStats(row scanner, extra ...any) (entity.UserQuestionStats, error) {
+	var stats entity.UserQuestionStats
+	var lastReviewedAt sql.NullTime
+	// 記得掃描進去時要小心 NULL 值，這裡假設 DB 欄位都有 NOT NULL 或 Default (last_reviewed_at 除外)
+	dest := []any{
+		&stats.ID, &stats.UserID, &stats.QuestionID, &stats.Streak, &stats.EaseFactor, &stats.IntervalDays,
+		&stats.IntervalMinutes, &stats.LearningStep, &stats.Status, &stats.NextReviewAt, &lastReviewedAt,
+		&stats.Stability, &stats.Difficulty, &stats.ReviewCount, &stats.Lapses, &stats.IsLeech,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	stats.LastReviewedAt = lastReviewedAt.Time
+	return stats, err
+}
+
 func (r *postgresRepository) UpsertUserStats(ctx context.Context, stats entity.UserQuestionStats) error {
+	return upsertStats(ctx, r.db, stats)
+}
+
+func (r *postgresRepository) UpsertUserStatsWithRelated(ctx context.Context, stats entity.UserQuestionStats, related []entity.RelatedAdjustment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := upsertStats(ctx, tx, stats); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, adj := range related {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE user_question_stats SET next_review_at = $3
+			WHERE user_id = $1 AND question_id = $2
+		`, stats.UserID, adj.QuestionID, adj.NextReviewAt)
+		if err != nil {
+			tx.Rollback() // 有一筆失敗就全部回滾，連同本題的狀態
+			return err
+		}
+
+		event := entity.CardEvent{
+			UserID:     stats.UserID,
+			QuestionID: adj.QuestionID,
+			Kind:       entity.CardEventRelated,
+			At:         stats.LastReviewedAt,
+			Grade:      adj.Grade,
+			Weight:     adj.Weight,
+		}
+		if err := createCardEvent(ctx, tx, event); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *postgresRepository) UpsertUserStatsWithEvent(ctx context.Context, stats entity.UserQuestionStats, event entity.CardEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := upsertStats(ctx, tx, stats); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := createCardEvent(ctx, tx, event); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *postgresRepository) BatchReplaceUserStats(ctx context.Context, stats []entity.UserQuestionStats, snapshots []entity.ReviewSnapshot) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stats {
+		if err := upsertStats(ctx, tx, s); err != nil {
+			tx.Rollback() // 有一筆失敗就整批回滾
+			return err
+		}
+		// 舊的快照是舊演算法算出來的，整題換掉
+		if _, err := tx.ExecContext(ctx, `DELETE FROM review_snapshots WHERE user_id = $1 AND question_id = $2`,
+			s.UserID, s.QuestionID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := insertSnapshots(ctx, tx, snapshots); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// execer 讓 *sql.DB 與 *sql.Tx 共用寫入邏輯
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func upsertStats(ctx context.Context, db execer, stats entity.UserQuestionStats) error {
 	// PostgreSQL 強大的 "ON CONFLICT" 語法
 	// 如果 (user_id, question_id) 已經存在，就 Update，否則 Insert
 	query := `
 		INSERT INTO user_question_stats (
-			user_id, question_id, streak, ease_factor, interval_days, next_review_at, last_reviewed_at, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			user_id, question_id, streak, ease_factor, interval_days, next_review_at, last_reviewed_at, status,
+			stability, difficulty, review_count, interval_minutes, learning_step, lapses, is_leech
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		ON CONFLICT (user_id, question_id) DO UPDATE SET
 			streak = EXCLUDED.streak,
 			ease_factor = EXCLUDED.ease_factor,
 			interval_days = EXCLUDED.interval_days,
 			next_review_at = EXCLUDED.next_review_at,
 			last_reviewed_at = EXCLUDED.last_reviewed_at,
-			status = EXCLUDED.status
+			status = EXCLUDED.status,
+			stability = EXCLUDED.stability,
+			difficulty = EXCLUDED.difficulty,
+			review_count = EXCLUDED.review_count,
+			interval_minutes = EXCLUDED.interval_minutes,
+			learning_step = EXCLUDED.learning_step,
+			lapses = EXCLUDED.lapses,
+			is_leech = EXCLUDED.is_leech
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.ExecContext(ctx, query,
 		stats.UserID, stats.QuestionID, stats.Streak, stats.EaseFactor,
 		stats.IntervalDays, stats.NextReviewAt, stats.LastReviewedAt, stats.Status,
+		stats.Stability, stats.Difficulty, stats.ReviewCount,
+		stats.IntervalMinutes, stats.LearningStep, stats.Lapses, stats.IsLeech,
 	)
 	return err
 }
 
+func (r *postgresRepository) ListRelatedStats(ctx context.Context, userID, questionID string) ([]entity.RelatedStats, error) {
+	// 關聯是雙向的：不論這題是 question_id 還是 related_id 都算
+	query := `
+		SELECT ` + statsColumns + `, rel.relation,
+			COALESCE((SELECT is_neetcode_150 FROM questions q WHERE q.id = user_question_stats.question_id), false)
+		FROM user_question_stats
+		JOIN (
+			SELECT related_id AS rq, relation FROM question_relations WHERE question_id = $2
+			UNION
+			SELECT question_id AS rq, relation FROM question_relations WHERE related_id = $2
+		) rel ON rel.rq = user_question_stats.question_id
+		WHERE user_id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var related []entity.RelatedStats
+	for rows.Next() {
+		var rs entity.RelatedStats
+		stats, err := scanStats(rows, &rs.Relation, &rs.IsNeetcode150)
+		if err != nil {
+			return nil, err
+		}
+		rs.UserQuestionStats = stats
+		related = append(related, rs)
+	}
+
+	return related, rows.Err()
+}
+
+func createCardEvent(ctx context.Context, db execer, event entity.CardEvent) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO card_events (user_id, question_id, kind, grade, weight, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, event.UserID, event.QuestionID, event.Kind, event.Grade, event.Weight, event.At)
+	return err
+}
+
+const cardEventColumns = `user_id, question_id, kind, grade, weight, occurred_at`
+
+func (r *postgresRepository) ListCardEvents(ctx context.Context, userID, questionID string) ([]entity.CardEvent, error) {
+	query := `SELECT ` + cardEventColumns + ` FROM card_events
+		WHERE user_id = $1 AND question_id = $2 ORDER BY occurred_at ASC`
+	return r.queryCardEvents(ctx, query, userID, questionID)
+}
+
+func (r *postgresRepository) ListUserCardEvents(ctx context.Context, userID string) ([]entity.CardEvent, error) {
+	query := `SELECT ` + cardEventColumns + ` FROM card_events
+		WHERE user_id = $1 ORDER BY occurred_at ASC`
+	return r.queryCardEvents(ctx, query, userID)
+}
+
+func (r *postgresRepository) queryCardEvents(ctx context.Context, query string, args ...any) ([]entity.CardEvent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []entity.CardEvent
+	for rows.Next() {
+		var e entity.CardEvent
+		if err := rows.Scan(&e.UserID, &e.QuestionID, &e.Kind, &e.Grade, &e.Weight, &e.At); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *postgresRepository) ListUserStats(ctx context.Context, userID string) ([]entity.UserQuestionStats, error) {
+	query := `
+		SELECT ` + statsColumns + `
+		FROM user_question_stats
+		WHERE user_id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []entity.UserQuestionStats
+	for rows.Next() {
+		stats, err := scanStats(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, stats)
+	}
+
+	return list, rows.Err()
+}
+
 // -------------------------------------------------------
 // Logs 實作
 // -------------------------------------------------------
 
 func (r *postgresRepository) CreateLog(ctx context.Context, log entity.SubmissionLog) error {
+	trace, err := marshalTrace(log.Trace)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO study_logs (user_id, question_id, status, mastery_level, attempted_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO study_logs (user_id, question_id, status, mastery_level, time_taken_seconds, attempted_at, trace)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
-	_, err := r.db.ExecContext(ctx, query, log.UserID, log.QuestionID, log.Status, log.MasteryLevel, log.Date)
+	_, err = r.db.ExecContext(ctx, query, log.UserID, log.QuestionID, log.Status, log.MasteryLevel, log.TimeTakenSeconds, log.Date, trace)
 	return err
 }
 
@@ -117,16 +382,23 @@ func (r *postgresRepository) BatchCreateLogs(ctx context.Context, logs []entity.
 	}
 
 	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO study_logs (user_id, question_id, status, attempted_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO study_logs (user_id, question_id, status, mastery_level, attempted_at, trace, external_id, submission_status)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), NULLIF($8, ''))
+		ON CONFLICT (user_id, external_id) DO NOTHING
 	`)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 	defer stmt.Close()
 
 	for _, log := range logs {
-		if _, err := stmt.ExecContext(ctx, log.UserID, log.QuestionID, log.Status, log.Date); err != nil {
+		trace, err := marshalTrace(log.Trace)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, log.UserID, log.QuestionID, log.Status, log.MasteryLevel, log.Date, trace, log.ExternalID, log.SubmissionStatus); err != nil {
 			tx.Rollback() // 有一筆失敗就全部回滾
 			return err
 		}
@@ -135,45 +407,1013 @@ func (r *postgresRepository) BatchCreateLogs(ctx context.Context, logs []entity.
 	return tx.Commit()
 }
 
-func (r *postgresRepository) GetDailyTasks(ctx context.Context, userID string, limit int) ([]entity.QuestionTask, error) {
-	// 邏輯解說：
-	// 1. 找出所有已經到期的 (next_review_at <= NOW()) 或是 全新的 (status = 'NEW')
-	// 2. 計算 priority：
-	//    - 如果是 NEW 或 interval=0，給予極高權重 (1000)，確保新題也會出現
-	//    - 否則計算 (Now - NextReview) / Interval
-	// 3. 取前 limit 筆 (例如 3 筆)
+func (r *postgresRepository) ListExternalIDs(ctx context.Context, userID string) (map[int64]bool, error) {
+	query := `SELECT external_id FROM study_logs WHERE user_id = $1 AND external_id IS NOT NULL`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	return ids, rows.Err()
+}
 
+func (r *postgresRepository) GetSyncCursor(ctx context.Context, userID string) (*entity.SyncCursor, error) {
 	query := `
-		SELECT
-			q.id, q.title, q.slug, q.difficulty, s.status, s.next_review_at,
-			EXTRACT(EPOCH FROM (NOW() - s.next_review_at)) / 86400.0 as overdue_days
-		FROM user_question_stats s
-		JOIN questions q ON s.question_id = q.id
-		WHERE s.user_id = $1
-		  AND (s.next_review_at <= NOW() OR s.status = 'NEW')
-		ORDER BY
-			CASE
-				WHEN s.interval_days = 0 THEN 1000.0
-				ELSE EXTRACT(EPOCH FROM (NOW() - s.next_review_at)) / (s.interval_days * 86400)
-			END DESC
-		LIMIT $2
+		SELECT user_id, last_submission_id, last_timestamp
+		FROM sync_cursors
+		WHERE user_id = $1
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	var c entity.SyncCursor
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&c.UserID, &c.LastSubmissionID, &c.LastTimestamp)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *postgresRepository) SaveSyncCursor(ctx context.Context, cursor entity.SyncCursor) error {
+	// 舊的一包晚到時不把游標往回拉
+	query := `
+		INSERT INTO sync_cursors (user_id, last_submission_id, last_timestamp, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			last_submission_id = GREATEST(sync_cursors.last_submission_id, EXCLUDED.last_submission_id),
+			last_timestamp = GREATEST(sync_cursors.last_timestamp, EXCLUDED.last_timestamp),
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, cursor.UserID, cursor.LastSubmissionID, cursor.LastTimestamp)
+	return err
+}
+
+func (r *postgresRepository) GetUserLogs(ctx context.Context, userID string) ([]entity.SubmissionLog, error) {
+	// 舊版的 BatchCreateLogs 沒有寫入 mastery_level，用 status 推回來
+	query := `
+		SELECT id, user_id, question_id, status,
+			COALESCE(mastery_level, CASE WHEN status = 'SOLVED' THEN 2 ELSE 0 END),
+			COALESCE(time_taken_seconds, 0), attempted_at, COALESCE(external_id, 0), COALESCE(submission_status, '')
+		FROM study_logs
+		WHERE user_id = $1
+		ORDER BY attempted_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tasks []entity.QuestionTask
+	var logs []entity.SubmissionLog
 	for rows.Next() {
-		var t entity.QuestionTask
-		// 掃描資料
-		if err := rows.Scan(&t.QuestionID, &t.Title, &t.Slug, &t.Difficulty, &t.Status, &t.NextReviewAt, &t.OverdueByDays); err != nil {
+		var l entity.SubmissionLog
+		if err := rows.Scan(&l.ID, &l.UserID, &l.QuestionID, &l.Status, &l.MasteryLevel, &l.TimeTakenSeconds, &l.Date, &l.ExternalID, &l.SubmissionStatus); err != nil {
 			return nil, err
 		}
-		tasks = append(tasks, t)
+		logs = append(logs, l)
+	}
+
+	return logs, rows.Err()
+}
+
+func (r *postgresRepository) GetQuestionLogs(ctx context.Context, userID, questionID string) ([]entity.SubmissionLog, error) {
+	query := `
+		SELECT id, user_id, question_id, status,
+			COALESCE(mastery_level, CASE WHEN status = 'SOLVED' THEN 2 ELSE 0 END),
+			COALESCE(time_taken_seconds, 0), attempted_at, COALESCE(external_id, 0), COALESCE(submission_status, ''), trace
+		FROM study_logs
+		WHERE user_id = $1 AND question_id = $2
+		ORDER BY attempted_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []entity.SubmissionLog
+	for rows.Next() {
+		var l entity.SubmissionLog
+		var trace []byte
+		if err := rows.Scan(&l.ID, &l.UserID, &l.QuestionID, &l.Status, &l.MasteryLevel, &l.TimeTakenSeconds, &l.Date, &l.ExternalID, &l.SubmissionStatus, &trace); err != nil {
+			return nil, err
+		}
+		if len(trace) > 0 {
+			if err := json.Unmarshal(trace, &l.Trace); err != nil {
+				return nil, err
+			}
+		}
+		logs = append(logs, l)
+	}
+
+	return logs, rows.Err()
+}
+
+func (r *postgresRepository) SaveSnapshots(ctx context.Context, snapshots []entity.ReviewSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := insertSnapshots(ctx, tx, snapshots); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertSnapshots 在 Transaction 裡寫入快照 (同一題同一時間的快照會被覆蓋)
+func insertSnapshots(ctx context.Context, tx *sql.Tx, snapshots []entity.ReviewSnapshot) error {
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO review_snapshots (
+			user_id, question_id, reviewed_at, grade, status, streak, ease_factor,
+			interval_days, interval_minutes, stability, difficulty, next_review_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (user_id, question_id, reviewed_at)
+		DO UPDATE SET
+			grade = EXCLUDED.grade,
+			status = EXCLUDED.status,
+			streak = EXCLUDED.streak,
+			ease_factor = EXCLUDED.ease_factor,
+			interval_days = EXCLUDED.interval_days,
+			interval_minutes = EXCLUDED.interval_minutes,
+			stability = EXCLUDED.stability,
+			difficulty = EXCLUDED.difficulty,
+			next_review_at = EXCLUDED.next_review_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, s := range snapshots {
+		if _, err := stmt.ExecContext(ctx,
+			s.UserID, s.QuestionID, s.ReviewedAt, s.Grade, s.Status, s.Streak, s.EaseFactor,
+			s.IntervalDays, s.IntervalMinutes, s.Stability, s.Difficulty, s.NextReviewAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *postgresRepository) GetSnapshots(ctx context.Context, userID, questionID string) ([]entity.ReviewSnapshot, error) {
+	query := `
+		SELECT user_id, question_id, reviewed_at, grade, status, streak, ease_factor,
+			interval_days, interval_minutes, stability, difficulty, next_review_at
+		FROM review_snapshots
+		WHERE user_id = $1 AND question_id = $2
+		ORDER BY reviewed_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []entity.ReviewSnapshot
+	for rows.Next() {
+		var s entity.ReviewSnapshot
+		if err := rows.Scan(
+			&s.UserID, &s.QuestionID, &s.ReviewedAt, &s.Grade, &s.Status, &s.Streak, &s.EaseFactor,
+			&s.IntervalDays, &s.IntervalMinutes, &s.Stability, &s.Difficulty, &s.NextReviewAt,
+		); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
 	}
 
-	return tasks, nil
+	return snapshots, rows.Err()
+}
+
+// marshalTrace 沒有 Trace 時寫入 NULL
+func marshalTrace(trace []entity.TraceStep) (any, error) {
+	if len(trace) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *postgresRepository) ListUserIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT user_id FROM study_logs`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	return userIDs, rows.Err()
+}
+
+func (r *postgresRepository) GetDailyTasks(ctx context.Context, userID string, limit int) ([]entity.QuestionTask, error) {
+	// 邏輯解說：
+	// 1. 找出所有已經到期的 (next_review_at <= NOW()) 或是 全新的 (status = 'NEW')
+	//    以及今天稍晚要再做的學習步驟 (LEARNING / RELEARNING)
+	// 2. 計算 priority：
+	//    - 已到期的學習步驟最優先 (2000)，剛答錯的題目要趁熱再做一次
+	//    - 如果是 NEW 或 interval=0，給予極高權重 (1000)，確保新題也會出現
+	//    - 否則計算 (Now - NextReview) / Interval (以分鐘計，學習步驟也適用)
+	// 3. 取前 limit 筆 (例如 3 筆)
+
+	query := `
+		SELECT ` + taskColumns + `
+		FROM user_question_stats s
+		JOIN questions q ON s.question_id = q.id
+		WHERE s.user_id = $1
+		  AND s.status <> 'SUSPENDED'
+		  AND (
+			s.next_review_at <= NOW()
+			OR s.status = 'NEW'
+			OR (s.status IN ('LEARNING', 'RELEARNING')
+				AND s.next_review_at < date_trunc('day', NOW()) + INTERVAL '1 day')
+		  )
+		ORDER BY
+			CASE
+				WHEN s.status IN ('LEARNING', 'RELEARNING') AND s.next_review_at <= NOW() THEN 2000.0
+				WHEN s.status = 'NEW' OR (s.interval_days = 0 AND s.interval_minutes = 0) THEN 1000.0
+				ELSE EXTRACT(EPOCH FROM (NOW() - s.next_review_at))
+					/ (GREATEST(s.interval_minutes, s.interval_days * 1440) * 60)
+			END DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTasks(rows)
+}
+
+func (r *postgresRepository) GetDeadlineTasks(ctx context.Context, userID string, deadline entity.InterviewDeadline, limit int) ([]entity.QuestionTask, error) {
+	// 邏輯解說 (面試模式)：
+	// 1. 除了一般的到期題目，範圍內、最後一週還沒複習過的題目一進入最後一週就列入
+	// 2. 範圍內的題目排在範圍外的前面
+	// 3. priority：
+	//    - 已到期的學習步驟最優先 (2000)
+	//    - 最後一週還沒複習過的 (1500)，面試前一定要再看一次
+	//    - NEW (1000)
+	//    - 否則以「距離上次練習的時間 / 距離面試的時間」排序：離面試越近、越久沒做的越前面
+	// 4. 取前 limit 筆
+
+	query := `
+		SELECT ` + taskColumns + `
+		FROM user_question_stats s
+		JOIN questions q ON s.question_id = q.id
+		CROSS JOIN LATERAL (
+			SELECT ($3 = 'all' OR COALESCE(q.is_neetcode_150, FALSE)) AS in_scope,
+				$2::timestamptz - INTERVAL '7 days' AS final_week
+		) d
+		WHERE s.user_id = $1
+		  AND s.status <> 'SUSPENDED'
+		  AND (
+			s.next_review_at <= NOW()
+			OR s.status = 'NEW'
+			OR (s.status IN ('LEARNING', 'RELEARNING')
+				AND s.next_review_at < date_trunc('day', NOW()) + INTERVAL '1 day')
+			OR (d.in_scope AND NOW() >= d.final_week
+				AND (s.last_reviewed_at IS NULL OR s.last_reviewed_at < d.final_week))
+		  )
+		ORDER BY
+			d.in_scope DESC,
+			CASE
+				WHEN s.status IN ('LEARNING', 'RELEARNING') AND s.next_review_at <= NOW() THEN 2000.0
+				WHEN d.in_scope AND NOW() >= d.final_week
+					AND (s.last_reviewed_at IS NULL OR s.last_reviewed_at < d.final_week) THEN 1500.0
+				WHEN s.status = 'NEW' OR s.last_reviewed_at IS NULL THEN 1000.0
+				ELSE EXTRACT(EPOCH FROM (NOW() - s.last_reviewed_at))
+					/ GREATEST(EXTRACT(EPOCH FROM ($2::timestamptz - NOW())), 86400)
+			END DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, deadline.Date, deadline.Scope, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTasks(rows)
+}
+
+func (r *postgresRepository) ListQuestionTasks(ctx context.Context, userID string) ([]entity.QuestionTask, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM user_question_stats s
+		JOIN questions q ON s.question_id = q.id
+		WHERE s.user_id = $1
+		  AND s.last_reviewed_at IS NOT NULL
+		  AND s.status <> 'SUSPENDED'
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTasks(rows)
+}
+
+func (r *postgresRepository) ListLeeches(ctx context.Context, userID string) ([]entity.QuestionTask, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM user_question_stats s
+		JOIN questions q ON s.question_id = q.id
+		WHERE s.user_id = $1 AND s.is_leech
+		ORDER BY s.lapses DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTasks(rows)
+}
+
+// taskColumns QuestionTask 共用的 SELECT 欄位 (順序需與 scanTasks 一致)
+const taskColumns = `
+	q.id, q.title, q.slug, COALESCE(q.difficulty, ''), s.status, s.next_review_at,
+	EXTRACT(EPOCH FROM (NOW() - s.next_review_at)) / 86400.0 as overdue_days,
+	s.interval_days, s.interval_minutes, s.stability, s.last_reviewed_at, s.lapses, s.is_leech
+`
+
+func scanTasks(rows *sql.Rows) ([]entity.QuestionTask, error) {
+	var tasks []entity.QuestionTask
+	for rows.Next() {
+		var t entity.QuestionTask
+		var lastReviewedAt sql.NullTime
+		// 掃描資料
+		if err := rows.Scan(
+			&t.QuestionID, &t.Title, &t.Slug, &t.Difficulty, &t.Status, &t.NextReviewAt, &t.OverdueByDays,
+			&t.IntervalDays, &t.IntervalMinutes, &t.Stability, &lastReviewedAt, &t.Lapses, &t.IsLeech,
+		); err != nil {
+			return nil, err
+		}
+		t.LastReviewedAt = lastReviewedAt.Time
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rows.Err()
+}
+
+func (r *postgresRepository) CountReviewsByDay(ctx context.Context, userID string, from, to time.Time) (map[string]int, error) {
+	query := `
+		SELECT to_char(next_review_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*)
+		FROM user_question_stats
+		WHERE user_id = $1
+		  AND status <> 'SUSPENDED'
+		  AND next_review_at >= $2 AND next_review_at < $3
+		GROUP BY day
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var day string
+		var count int
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, err
+		}
+		counts[day] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// -------------------------------------------------------
+// Settings 實作
+// -------------------------------------------------------
+
+func (r *postgresRepository) GetUserAlgorithm(ctx context.Context, userID string) (string, error) {
+	query := `SELECT algorithm FROM user_settings WHERE user_id = $1`
+
+	var algorithm string
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&algorithm)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// 沒設定過 -> 讓 Service 使用預設演算法
+			return "", nil
+		}
+		return "", err
+	}
+	return algorithm, nil
+}
+
+func (r *postgresRepository) SetUserAlgorithm(ctx context.Context, userID, algorithm string) error {
+	query := `
+		INSERT INTO user_settings (user_id, algorithm, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			algorithm = EXCLUDED.algorithm,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, algorithm)
+	return err
+}
+
+func (r *postgresRepository) GetSchedulerParams(ctx context.Context, userID string) (*entity.SchedulerParams, error) {
+	query := `
+		SELECT user_id, hard_modifier, easy_bonus, retention_bonus,
+			log_loss, baseline_log_loss, review_count, fitted_at
+		FROM user_scheduler_params
+		WHERE user_id = $1
+	`
+
+	var p entity.SchedulerParams
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&p.UserID, &p.HardModifier, &p.EasyBonus, &p.RetentionBonus,
+		&p.LogLoss, &p.BaselineLogLoss, &p.ReviewCount, &p.FittedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *postgresRepository) SaveSchedulerParams(ctx context.Context, p entity.SchedulerParams) error {
+	query := `
+		INSERT INTO user_scheduler_params (
+			user_id, hard_modifier, easy_bonus, retention_bonus,
+			log_loss, baseline_log_loss, review_count, fitted_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			hard_modifier = EXCLUDED.hard_modifier,
+			easy_bonus = EXCLUDED.easy_bonus,
+			retention_bonus = EXCLUDED.retention_bonus,
+			log_loss = EXCLUDED.log_loss,
+			baseline_log_loss = EXCLUDED.baseline_log_loss,
+			review_count = EXCLUDED.review_count,
+			fitted_at = EXCLUDED.fitted_at
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		p.UserID, p.HardModifier, p.EasyBonus, p.RetentionBonus,
+		p.LogLoss, p.BaselineLogLoss, p.ReviewCount, p.FittedAt,
+	)
+	return err
+}
+
+func (r *postgresRepository) GetSchedulerConfig(ctx context.Context, userID string) (*entity.SchedulerConfig, error) {
+	query := `
+		SELECT user_id, maximum_interval, minimum_ef, hard_modifier, easy_bonus,
+			second_interval_hard, second_interval_good, second_interval_easy,
+			fuzz_threshold_days, fuzz_range
+		FROM user_scheduler_configs
+		WHERE user_id = $1
+	`
+
+	var c entity.SchedulerConfig
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&c.UserID, &c.MaximumInterval, &c.MinimumEF, &c.HardModifier, &c.EasyBonus,
+		&c.SecondIntervals[0], &c.SecondIntervals[1], &c.SecondIntervals[2],
+		&c.FuzzThresholdDays, &c.FuzzRange,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *postgresRepository) SaveSchedulerConfig(ctx context.Context, c entity.SchedulerConfig) error {
+	query := `
+		INSERT INTO user_scheduler_configs (
+			user_id, maximum_interval, minimum_ef, hard_modifier, easy_bonus,
+			second_interval_hard, second_interval_good, second_interval_easy,
+			fuzz_threshold_days, fuzz_range, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			maximum_interval = EXCLUDED.maximum_interval,
+			minimum_ef = EXCLUDED.minimum_ef,
+			hard_modifier = EXCLUDED.hard_modifier,
+			easy_bonus = EXCLUDED.easy_bonus,
+			second_interval_hard = EXCLUDED.second_interval_hard,
+			second_interval_good = EXCLUDED.second_interval_good,
+			second_interval_easy = EXCLUDED.second_interval_easy,
+			fuzz_threshold_days = EXCLUDED.fuzz_threshold_days,
+			fuzz_range = EXCLUDED.fuzz_range,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		c.UserID, c.MaximumInterval, c.MinimumEF, c.HardModifier, c.EasyBonus,
+		c.SecondIntervals[0], c.SecondIntervals[1], c.SecondIntervals[2],
+		c.FuzzThresholdDays, c.FuzzRange,
+	)
+	return err
+}
+
+func (r *postgresRepository) GetInitialEasePolicy(ctx context.Context, userID string) (*entity.InitialEasePolicy, error) {
+	query := `
+		SELECT difficulties, categories
+		FROM user_initial_ease_policies
+		WHERE user_id = $1
+	`
+
+	policy := entity.InitialEasePolicy{UserID: userID}
+	var difficulties, categories []byte
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&difficulties, &categories)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(difficulties, &policy.Difficulties); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(categories, &policy.Categories); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *postgresRepository) SaveInitialEasePolicy(ctx context.Context, policy entity.InitialEasePolicy) error {
+	difficulties, err := json.Marshal(policy.Difficulties)
+	if err != nil {
+		return err
+	}
+	categories, err := json.Marshal(policy.Categories)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_initial_ease_policies (user_id, difficulties, categories, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			difficulties = EXCLUDED.difficulties,
+			categories = EXCLUDED.categories,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = r.db.ExecContext(ctx, query, policy.UserID, difficulties, categories)
+	return err
+}
+
+func (r *postgresRepository) GetStatusGradeMapping(ctx context.Context, userID string) (*entity.StatusGradeMapping, error) {
+	query := `SELECT statuses FROM user_status_grade_mappings WHERE user_id = $1`
+
+	mapping := entity.StatusGradeMapping{UserID: userID}
+	var statuses []byte
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&statuses)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(statuses, &mapping.Statuses); err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func (r *postgresRepository) SaveStatusGradeMapping(ctx context.Context, mapping entity.StatusGradeMapping) error {
+	statuses, err := json.Marshal(mapping.Statuses)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_status_grade_mappings (user_id, statuses, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			statuses = EXCLUDED.statuses,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = r.db.ExecContext(ctx, query, mapping.UserID, statuses)
+	return err
+}
+
+func (r *postgresRepository) GetGradingPolicy(ctx context.Context, userID string) (*entity.GradingPolicy, error) {
+	query := `
+		SELECT thresholds, hard_after_wrong_attempts, hints_cap_grade
+		FROM user_grading_policies
+		WHERE user_id = $1
+	`
+
+	policy := entity.GradingPolicy{UserID: userID}
+	var thresholds []byte
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&thresholds, &policy.HardAfterWrongAttempts, &policy.HintsCapGrade,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(thresholds, &policy.Thresholds); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *postgresRepository) SaveGradingPolicy(ctx context.Context, policy entity.GradingPolicy) error {
+	thresholds, err := json.Marshal(policy.Thresholds)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_grading_policies (user_id, thresholds, hard_after_wrong_attempts, hints_cap_grade, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			thresholds = EXCLUDED.thresholds,
+			hard_after_wrong_attempts = EXCLUDED.hard_after_wrong_attempts,
+			hints_cap_grade = EXCLUDED.hints_cap_grade,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = r.db.ExecContext(ctx, query, policy.UserID, thresholds, policy.HardAfterWrongAttempts, policy.HintsCapGrade)
+	return err
+}
+
+func (r *postgresRepository) GetLeechPolicy(ctx context.Context, userID string) (*entity.LeechPolicy, error) {
+	query := `SELECT leech_threshold, leech_action FROM user_settings WHERE user_id = $1`
+
+	var policy entity.LeechPolicy
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&policy.Threshold, &policy.Action)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *postgresRepository) SaveLeechPolicy(ctx context.Context, userID string, policy entity.LeechPolicy) error {
+	query := `
+		INSERT INTO user_settings (user_id, leech_threshold, leech_action, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			leech_threshold = EXCLUDED.leech_threshold,
+			leech_action = EXCLUDED.leech_action,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, policy.Threshold, policy.Action)
+	return err
+}
+
+func (r *postgresRepository) GetSessionWindow(ctx context.Context, userID string) (*int, error) {
+	query := `SELECT session_window_minutes FROM user_settings WHERE user_id = $1`
+
+	var minutes sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&minutes)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !minutes.Valid {
+		return nil, nil
+	}
+	m := int(minutes.Int64)
+	return &m, nil
+}
+
+func (r *postgresRepository) SaveSessionWindow(ctx context.Context, userID string, minutes int) error {
+	query := `
+		INSERT INTO user_settings (user_id, session_window_minutes, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			session_window_minutes = EXCLUDED.session_window_minutes,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, minutes)
+	return err
+}
+
+func (r *postgresRepository) GetInterviewDeadline(ctx context.Context, userID string) (*entity.InterviewDeadline, error) {
+	query := `SELECT interview_date, interview_scope FROM user_settings WHERE user_id = $1`
+
+	var date sql.NullTime
+	var scope sql.NullString
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&date, &scope)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !date.Valid {
+		return nil, nil
+	}
+	return &entity.InterviewDeadline{Date: date.Time, Scope: scope.String}, nil
+}
+
+func (r *postgresRepository) SaveInterviewDeadline(ctx context.Context, userID string, deadline *entity.InterviewDeadline) error {
+	var date sql.NullTime
+	scope := entity.DeadlineScopeAll
+	if deadline != nil {
+		date = sql.NullTime{Time: deadline.Date, Valid: true}
+		scope = deadline.Scope
+	}
+
+	query := `
+		INSERT INTO user_settings (user_id, interview_date, interview_scope, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			interview_date = EXCLUDED.interview_date,
+			interview_scope = EXCLUDED.interview_scope,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, date, scope)
+	return err
+}
+
+// -------------------------------------------------------
+// Question Relations 實作
+// -------------------------------------------------------
+
+func (r *postgresRepository) ListRelations(ctx context.Context, questionID string) ([]entity.QuestionRelation, error) {
+	query := `
+		SELECT question_id, related_id, relation
+		FROM question_relations
+		WHERE question_id = $1 OR related_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var relations []entity.QuestionRelation
+	for rows.Next() {
+		var rel entity.QuestionRelation
+		if err := rows.Scan(&rel.QuestionID, &rel.RelatedID, &rel.Relation); err != nil {
+			return nil, err
+		}
+		relations = append(relations, rel)
+	}
+
+	return relations, rows.Err()
+}
+
+func (r *postgresRepository) SaveRelation(ctx context.Context, rel entity.QuestionRelation) error {
+	query := `
+		INSERT INTO question_relations (question_id, related_id, relation)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (question_id, related_id) DO UPDATE SET relation = EXCLUDED.relation
+	`
+	_, err := r.db.ExecContext(ctx, query, rel.QuestionID, rel.RelatedID, rel.Relation)
+	return err
+}
+
+func (r *postgresRepository) DeleteRelation(ctx context.Context, questionID, relatedID string) error {
+	query := `
+		DELETE FROM question_relations
+		WHERE (question_id = $1 AND related_id = $2) OR (question_id = $2 AND related_id = $1)
+	`
+	_, err := r.db.ExecContext(ctx, query, questionID, relatedID)
+	return err
+}
+
+func (r *postgresRepository) GetRelatedCreditPolicy(ctx context.Context, userID string) (*entity.RelatedCreditPolicy, error) {
+	query := `SELECT related_success_credit, related_failure_pull FROM user_settings WHERE user_id = $1`
+
+	var policy entity.RelatedCreditPolicy
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&policy.SuccessCredit, &policy.FailurePull)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *postgresRepository) SaveRelatedCreditPolicy(ctx context.Context, userID string, policy entity.RelatedCreditPolicy) error {
+	query := `
+		INSERT INTO user_settings (user_id, related_success_credit, related_failure_pull, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			related_success_credit = EXCLUDED.related_success_credit,
+			related_failure_pull = EXCLUDED.related_failure_pull,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, policy.SuccessCredit, policy.FailurePull)
+	return err
+}
+
+// -------------------------------------------------------
+// Import Jobs 實作
+// -------------------------------------------------------
+
+const importJobColumns = `
+	id, COALESCE(user_id::text, ''), kind, status, total_records, total_slugs, processed_slugs, failed_slugs,
+	imported, skipped, ignored, COALESCE(error, ''), progress, created_at, started_at, finished_at
+`
+
+func (r *postgresRepository) CreateImportJob(ctx context.Context, job entity.ImportJob, payload []byte) (string, error) {
+	query := `
+		INSERT INTO import_jobs (user_id, kind, status, total_records, total_slugs, payload)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	var id string
+	err := r.db.QueryRowContext(ctx, query, job.UserID, job.Kind, entity.ImportJobQueued, job.TotalRecords, job.TotalSlugs, payload).Scan(&id)
+	return id, err
+}
+
+func (r *postgresRepository) ClaimImportJob(ctx context.Context) (*entity.ImportJob, []byte, error) {
+	// SKIP LOCKED：多個 Worker 同時搶工作時，各自拿到不同的那一筆
+	// 同一位使用者已經有工作在跑就先跳過 (兩個 Worker 同時搶到同一人的工作時，還有 LockUser 擋著)
+	query := `
+		UPDATE import_jobs SET status = $1, started_at = NOW()
+		WHERE id = (
+			SELECT id FROM import_jobs j
+			WHERE status = $2
+				AND NOT EXISTS (
+					SELECT 1 FROM import_jobs r WHERE r.user_id = j.user_id AND r.status = $1
+				)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + importJobColumns + `, payload`
+
+	var payload []byte
+	job, err := scanImportJob(r.db.QueryRowContext(ctx, query, entity.ImportJobRunning, entity.ImportJobQueued), &payload)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	return job, payload, nil
+}
+
+func (r *postgresRepository) LockUser(ctx context.Context, userID string) (func(), error) {
+	// advisory lock 綁在連線上：拿一條專用連線上鎖，解鎖後再還給連線池
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtext($1))`, userID); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return func() {
+		// ctx 可能已經取消，解鎖用 Background
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, userID); err != nil {
+			log.Printf("failed to unlock user %s: %v", userID, err)
+			// 解鎖失敗就把連線丟掉 (斷線時 PostgreSQL 會自動釋放鎖)，不能帶著鎖回到連線池
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}, nil
+}
+
+func (r *postgresRepository) UpdateImportJob(ctx context.Context, job entity.ImportJob) error {
+	var progress []byte
+	var err error
+	if job.Kind == entity.JobKindRecompute {
+		progress, err = json.Marshal(job.Recompute)
+	} else {
+		progress, err = json.Marshal(job.Slugs)
+	}
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE import_jobs SET
+			status = $2,
+			processed_slugs = $3,
+			failed_slugs = $4,
+			imported = $5,
+			skipped = $6,
+			ignored = $7,
+			error = NULLIF($8, ''),
+			progress = $9,
+			finished_at = $10
+		WHERE id = $1
+	`
+	var finishedAt sql.NullTime
+	if job.FinishedAt != nil {
+		finishedAt = sql.NullTime{Time: *job.FinishedAt, Valid: true}
+	}
+	_, err = r.db.ExecContext(ctx, query, job.ID, job.Status, job.ProcessedSlugs, job.FailedSlugs,
+		job.Imported, job.Skipped, job.Ignored, job.Error, progress, finishedAt)
+	return err
+}
+
+func (r *postgresRepository) GetImportJob(ctx context.Context, userID, jobID string) (*entity.ImportJob, error) {
+	query := `SELECT ` + importJobColumns + ` FROM import_jobs WHERE id = $1 AND user_id = $2`
+
+	job, err := scanImportJob(r.db.QueryRowContext(ctx, query, jobID, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *postgresRepository) GetRecomputeJob(ctx context.Context, jobID string) (*entity.ImportJob, error) {
+	query := `SELECT ` + importJobColumns + ` FROM import_jobs WHERE id = $1 AND kind = $2`
+
+	job, err := scanImportJob(r.db.QueryRowContext(ctx, query, jobID, entity.JobKindRecompute))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *postgresRepository) RequeueRunningImportJobs(ctx context.Context) (int, error) {
+	query := `UPDATE import_jobs SET status = $1, started_at = NULL WHERE status = $2`
+	res, err := r.db.ExecContext(ctx, query, entity.ImportJobQueued, entity.ImportJobRunning)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// scanImportJob 依 importJobColumns 的順序掃描，extra 為額外選取的欄位
+func scanImportJob(row scanner, extra ...any) (*entity.ImportJob, error) {
+	var job entity.ImportJob
+	var progress []byte
+	var startedAt, finishedAt sql.NullTime
+
+	dest := []any{
+		&job.ID, &job.UserID, &job.Kind, &job.Status, &job.TotalRecords, &job.TotalSlugs, &job.ProcessedSlugs, &job.FailedSlugs,
+		&job.Imported, &job.Skipped, &job.Ignored, &job.Error, &progress, &job.CreatedAt, &startedAt, &finishedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if len(progress) > 0 {
+		var err error
+		if job.Kind == entity.JobKindRecompute {
+			err = json.Unmarshal(progress, &job.Recompute)
+		} else {
+			err = json.Unmarshal(progress, &job.Slugs)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
 }
@@ -2,26 +2,141 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"letracker/internal/entity"
+	"time"
 )
 
+// ErrQuestionNotFound 題目不存在 (GetQuestionBySlug / GetQuestionByID 沒找到時回傳)
+var ErrQuestionNotFound = errors.New("question not found")
+
 // Repository 定義了所有資料庫操作的方法
 // 這樣做的好處是方便未來寫單元測試 (Mocking)
 type Repository interface {
 	// Question 相關
 	GetQuestionBySlug(ctx context.Context, slug string) (*entity.Question, error)
+	GetQuestionByID(ctx context.Context, id string) (*entity.Question, error)
 	CreateQuestion(ctx context.Context, q entity.Question) (string, error) // 回傳 ID
+	// 補上題目的難度與分類 (空字串代表不更新該欄位)
+	UpdateQuestionMeta(ctx context.Context, id, difficulty, category string) error
 
 	// Stats (SRS 狀態) 相關
 	// 取得某使用者對某題的狀態
 	GetUserStats(ctx context.Context, userID, questionID string) (*entity.UserQuestionStats, error)
 	// 更新或插入狀態 (Upsert)
 	UpsertUserStats(ctx context.Context, stats entity.UserQuestionStats) error
+	// 取得使用者所有題目的狀態
+	ListUserStats(ctx context.Context, userID string) ([]entity.UserQuestionStats, error)
+	// 在同一個 Transaction 裡更新本題狀態，並調整相關題目的下次複習時間 (同時記下 CardEvent)
+	UpsertUserStatsWithRelated(ctx context.Context, stats entity.UserQuestionStats, related []entity.RelatedAdjustment) error
+	// 在同一個 Transaction 裡更新本題狀態並記下造成改變的事件 (解除暫停、重設等)
+	UpsertUserStatsWithEvent(ctx context.Context, stats entity.UserQuestionStats, event entity.CardEvent) error
+	// 在同一個 Transaction 裡更新多題的狀態，並以 snapshots 取代這些題目原本的快照 (重算用)
+	BatchReplaceUserStats(ctx context.Context, stats []entity.UserQuestionStats, snapshots []entity.ReviewSnapshot) error
+	// 取得與某題相關 (雙向) 且使用者練習過的題目狀態
+	ListRelatedStats(ctx context.Context, userID, questionID string) ([]entity.RelatedStats, error)
+
+	// Card Events (練習以外改動排程的操作) 相關
+	// 取得單題的事件 (按時間從舊到新)
+	ListCardEvents(ctx context.Context, userID, questionID string) ([]entity.CardEvent, error)
+	// 取得使用者全部的事件 (按時間從舊到新)
+	ListUserCardEvents(ctx context.Context, userID string) ([]entity.CardEvent, error)
+
+	// Question Relations (題目關聯圖) 相關
+	ListRelations(ctx context.Context, questionID string) ([]entity.QuestionRelation, error)
+	SaveRelation(ctx context.Context, rel entity.QuestionRelation) error // Upsert
+	DeleteRelation(ctx context.Context, questionID, relatedID string) error
 
 	// Logs (流水帳) 相關
 	CreateLog(ctx context.Context, log entity.SubmissionLog) error
-	// 批次寫入 Logs (給匯入歷史紀錄用)
+	// 批次寫入 Logs (給匯入歷史紀錄用)，ExternalID 重複的紀錄會被略過
 	BatchCreateLogs(ctx context.Context, logs []entity.SubmissionLog) error
+	// 取得使用者已經匯入過的 LeetCode submission id
+	ListExternalIDs(ctx context.Context, userID string) (map[int64]bool, error)
+	// 取得使用者的同步游標，沒同步過回傳 nil
+	GetSyncCursor(ctx context.Context, userID string) (*entity.SyncCursor, error)
+	// 儲存同步游標 (Upsert，只會往前推進)
+	SaveSyncCursor(ctx context.Context, cursor entity.SyncCursor) error
+
+	// 取得使用者層級的鎖，同一位使用者的即時評分、匯入與重算依序執行 (讀到的狀態不會被別人覆蓋)
+	// 回傳的 unlock 一定要呼叫，鎖才會釋放
+	LockUser(ctx context.Context, userID string) (unlock func(), err error)
+
+	// Import Jobs (背景匯入) 相關
+	// 建立一個 queued 的工作 (匯入或重算)，payload 為工作的內容 (JSON)，回傳 ID
+	CreateImportJob(ctx context.Context, job entity.ImportJob, payload []byte) (string, error)
+	// 取出最早的 queued 工作並標記為 running (多個 Worker 不會拿到同一個)，沒有工作回傳 nil
+	// 已經有 running 工作的使用者會被跳過，同一位使用者的工作依序執行
+	ClaimImportJob(ctx context.Context) (*entity.ImportJob, []byte, error)
+	// 更新工作的狀態與進度
+	UpdateImportJob(ctx context.Context, job entity.ImportJob) error
+	// 取得使用者的匯入工作，不存在回傳 nil
+	GetImportJob(ctx context.Context, userID, jobID string) (*entity.ImportJob, error)
+	// 取得重算工作 (管理用，不限使用者)，不存在回傳 nil
+	GetRecomputeJob(ctx context.Context, jobID string) (*entity.ImportJob, error)
+	// 把停在 running 的工作 (例如 Server 中途重啟) 放回 queued，回傳筆數
+	RequeueRunningImportJobs(ctx context.Context) (int, error)
+
+	// 取得使用者全部的 Logs (按時間從舊到新)
+	GetUserLogs(ctx context.Context, userID string) ([]entity.SubmissionLog, error)
+	// 取得單題的 Logs (按時間從舊到新，含排程的計算過程)
+	GetQuestionLogs(ctx context.Context, userID, questionID string) ([]entity.SubmissionLog, error)
+	// 儲存每次練習後的狀態快照 (同一題同一時間的快照會被覆蓋，重跑匯入不會重複)
+	SaveSnapshots(ctx context.Context, snapshots []entity.ReviewSnapshot) error
+	// 取得單題的狀態快照 (按時間從舊到新)
+	GetSnapshots(ctx context.Context, userID, questionID string) ([]entity.ReviewSnapshot, error)
+	// 列出所有有練習紀錄的使用者
+	ListUserIDs(ctx context.Context) ([]string, error)
 	// GetDailyTasks: 撈出今天需要做的題目 (含題目詳細資訊)
 	GetDailyTasks(ctx context.Context, userID string, limit int) ([]entity.QuestionTask, error)
+	// GetDeadlineTasks: 面試模式的每日任務，依面試日期排序 (範圍內、最後一週還沒複習過的優先)
+	GetDeadlineTasks(ctx context.Context, userID string, deadline entity.InterviewDeadline, limit int) ([]entity.QuestionTask, error)
+	// ListQuestionTasks: 撈出使用者練習過的所有題目 (不限到期)，給「依記憶保留率篩選」使用
+	ListQuestionTasks(ctx context.Context, userID string) ([]entity.QuestionTask, error)
+	// ListLeeches: 撈出被標記為 leech 的題目 (含已暫停的)
+	ListLeeches(ctx context.Context, userID string) ([]entity.QuestionTask, error)
+	// 統計 [from, to) 之間每天排了幾題複習 (Key 為 UTC 日期 "2006-01-02")
+	CountReviewsByDay(ctx context.Context, userID string, from, to time.Time) (map[string]int, error)
+
+	// Settings (使用者設定) 相關
+	// 取得使用者選用的排程演算法，沒設定過回傳空字串
+	GetUserAlgorithm(ctx context.Context, userID string) (string, error)
+	// 設定使用者的排程演算法 (Upsert)
+	SetUserAlgorithm(ctx context.Context, userID, algorithm string) error
+	// 取得 optimizer 擬合的參數，沒擬合過回傳 nil
+	GetSchedulerParams(ctx context.Context, userID string) (*entity.SchedulerParams, error)
+	// 儲存 optimizer 擬合的參數 (Upsert)
+	SaveSchedulerParams(ctx context.Context, params entity.SchedulerParams) error
+	// 取得使用者的排程設定，沒設定過回傳 nil
+	GetSchedulerConfig(ctx context.Context, userID string) (*entity.SchedulerConfig, error)
+	// 儲存使用者的排程設定 (Upsert)
+	SaveSchedulerConfig(ctx context.Context, config entity.SchedulerConfig) error
+	// 取得使用者的新題初始 EF 設定，沒設定過回傳 nil
+	GetInitialEasePolicy(ctx context.Context, userID string) (*entity.InitialEasePolicy, error)
+	// 儲存使用者的新題初始 EF 設定 (Upsert)
+	SaveInitialEasePolicy(ctx context.Context, policy entity.InitialEasePolicy) error
+	// 取得使用者的匯入狀態對應表，沒設定過回傳 nil
+	GetStatusGradeMapping(ctx context.Context, userID string) (*entity.StatusGradeMapping, error)
+	// 儲存使用者的匯入狀態對應表 (Upsert)
+	SaveStatusGradeMapping(ctx context.Context, mapping entity.StatusGradeMapping) error
+	// 取得匯入時合併提交的時間窗 (分鐘)，沒設定過回傳 nil
+	GetSessionWindow(ctx context.Context, userID string) (*int, error)
+	// 儲存合併提交的時間窗 (Upsert)
+	SaveSessionWindow(ctx context.Context, userID string, minutes int) error
+	// 取得使用者的評分門檻，沒設定過回傳 nil
+	GetGradingPolicy(ctx context.Context, userID string) (*entity.GradingPolicy, error)
+	// 儲存使用者的評分門檻 (Upsert)
+	SaveGradingPolicy(ctx context.Context, policy entity.GradingPolicy) error
+	// 取得使用者的 leech 設定，沒設定過回傳 nil
+	GetLeechPolicy(ctx context.Context, userID string) (*entity.LeechPolicy, error)
+	// 儲存使用者的 leech 設定 (Upsert)
+	SaveLeechPolicy(ctx context.Context, userID string, policy entity.LeechPolicy) error
+	// 取得使用者的相關題目連動設定，沒設定過回傳 nil
+	GetRelatedCreditPolicy(ctx context.Context, userID string) (*entity.RelatedCreditPolicy, error)
+	// 儲存使用者的相關題目連動設定 (Upsert)
+	SaveRelatedCreditPolicy(ctx context.Context, userID string, policy entity.RelatedCreditPolicy) error
+	// 取得使用者的面試日期，沒設定過回傳 nil
+	GetInterviewDeadline(ctx context.Context, userID string) (*entity.InterviewDeadline, error)
+	// 儲存使用者的面試日期 (Upsert)；deadline 為 nil 代表取消面試模式
+	SaveInterviewDeadline(ctx context.Context, userID string, deadline *entity.InterviewDeadline) error
 }
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+// ErrInvalidDeadline 面試日期或範圍不合理
+var ErrInvalidDeadline = errors.New("invalid interview deadline")
+
+// GetInterviewDeadline 讀取面試模式設定，沒設定 (或面試已經過了) 回傳 nil
+func (s *reviewServiceImpl) GetInterviewDeadline(ctx context.Context, userID string) (*entity.InterviewDeadline, error) {
+	deadline, err := s.repo.GetInterviewDeadline(ctx, userID)
+	if err != nil || deadline == nil {
+		return nil, err
+	}
+	if !deadline.Date.After(s.clock.Now()) {
+		return nil, nil
+	}
+	if deadline.Scope == "" {
+		deadline.Scope = entity.DeadlineScopeAll
+	}
+	return deadline, nil
+}
+
+// SetInterviewDeadline 設定面試日期 (只看日期，統一存成當天 00:00 UTC)
+func (s *reviewServiceImpl) SetInterviewDeadline(ctx context.Context, userID string, deadline entity.InterviewDeadline) (*entity.InterviewDeadline, error) {
+	if deadline.Scope == "" {
+		deadline.Scope = entity.DeadlineScopeAll
+	}
+	if deadline.Scope != entity.DeadlineScopeAll && deadline.Scope != entity.DeadlineScopeNeetCode150 {
+		return nil, fmt.Errorf("%w: scope must be %q or %q", ErrInvalidDeadline, entity.DeadlineScopeAll, entity.DeadlineScopeNeetCode150)
+	}
+
+	y, m, d := deadline.Date.UTC().Date()
+	deadline.Date = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if !deadline.Date.After(s.clock.Now()) {
+		return nil, fmt.Errorf("%w: date must be in the future", ErrInvalidDeadline)
+	}
+
+	if err := s.repo.SaveInterviewDeadline(ctx, userID, &deadline); err != nil {
+		return nil, err
+	}
+	return &deadline, nil
+}
+
+// ClearInterviewDeadline 取消面試模式
+func (s *reviewServiceImpl) ClearInterviewDeadline(ctx context.Context, userID string) error {
+	return s.repo.SaveInterviewDeadline(ctx, userID, nil)
+}
+
+// deadlineFor 回傳這題要套用的面試日期 (沒設定或不在範圍內回傳零值)
+func (s *reviewServiceImpl) deadlineFor(ctx context.Context, deadline *entity.InterviewDeadline, questionID string) (time.Time, error) {
+	if deadline == nil {
+		return time.Time{}, nil
+	}
+	if deadline.Scope == entity.DeadlineScopeNeetCode150 {
+		q, err := s.repo.GetQuestionByID(ctx, questionID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return deadlineForQuestion(deadline, q), nil
+	}
+	return deadline.Date, nil
+}
+
+// deadlineForQuestion 與 deadlineFor 相同，但題目已經在手上 (匯入時不必再查一次 DB)
+func deadlineForQuestion(deadline *entity.InterviewDeadline, q *entity.Question) time.Time {
+	if deadline == nil {
+		return time.Time{}
+	}
+	if deadline.Scope == entity.DeadlineScopeNeetCode150 && !q.IsNeetcode150 {
+		return time.Time{}
+	}
+	return deadline.Date
+}
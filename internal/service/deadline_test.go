@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+func TestSetInterviewDeadline(t *testing.T) {
+	now := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		deadline entity.InterviewDeadline
+		want     *entity.InterviewDeadline
+	}{
+		{
+			name:     "normalized to midnight UTC with the default scope",
+			deadline: entity.InterviewDeadline{Date: time.Date(2026, 3, 20, 18, 30, 0, 0, time.UTC)},
+			want:     &entity.InterviewDeadline{Date: time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC), Scope: entity.DeadlineScopeAll},
+		},
+		{
+			name:     "NeetCode 150 scope",
+			deadline: entity.InterviewDeadline{Date: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), Scope: entity.DeadlineScopeNeetCode150},
+			want:     &entity.InterviewDeadline{Date: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), Scope: entity.DeadlineScopeNeetCode150},
+		},
+		{name: "today is not in the future", deadline: entity.InterviewDeadline{Date: now.Add(2 * time.Hour)}},
+		{name: "in the past", deadline: entity.InterviewDeadline{Date: now.AddDate(0, 0, -1)}},
+		{name: "unknown scope", deadline: entity.InterviewDeadline{Date: now.AddDate(0, 0, 10), Scope: "blind_75"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{}
+			svc := newTestService(repo, now)
+
+			got, err := svc.SetInterviewDeadline(context.Background(), "u", tt.deadline)
+			if tt.want == nil {
+				if !errors.Is(err, ErrInvalidDeadline) {
+					t.Fatalf("SetInterviewDeadline() error = %v, want ErrInvalidDeadline", err)
+				}
+				if repo.deadline != nil {
+					t.Error("an invalid deadline should not be saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetInterviewDeadline() error = %v", err)
+			}
+			if *got != *tt.want || *repo.deadline != *tt.want {
+				t.Errorf("saved %+v, returned %+v, want %+v", *repo.deadline, *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestGetInterviewDeadline(t *testing.T) {
+	now := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		stored *entity.InterviewDeadline
+		want   *entity.InterviewDeadline
+	}{
+		{name: "not set"},
+		{name: "interview is over", stored: &entity.InterviewDeadline{Date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Scope: entity.DeadlineScopeAll}},
+		{
+			name:   "older rows without a scope cover every question",
+			stored: &entity.InterviewDeadline{Date: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)},
+			want:   &entity.InterviewDeadline{Date: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), Scope: entity.DeadlineScopeAll},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(&fakeRepo{deadline: tt.stored}, now)
+			got, err := svc.GetInterviewDeadline(context.Background(), "u")
+			if err != nil {
+				t.Fatalf("GetInterviewDeadline() error = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("GetInterviewDeadline() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeadlineForQuestion(t *testing.T) {
+	date := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	neetcode := &entity.Question{IsNeetcode150: true}
+	other := &entity.Question{}
+
+	tests := []struct {
+		name     string
+		deadline *entity.InterviewDeadline
+		question *entity.Question
+		want     time.Time
+	}{
+		{name: "no interview", question: neetcode},
+		{name: "all questions", deadline: &entity.InterviewDeadline{Date: date, Scope: entity.DeadlineScopeAll}, question: other, want: date},
+		{name: "NeetCode 150 question", deadline: &entity.InterviewDeadline{Date: date, Scope: entity.DeadlineScopeNeetCode150}, question: neetcode, want: date},
+		{name: "outside NeetCode 150", deadline: &entity.InterviewDeadline{Date: date, Scope: entity.DeadlineScopeNeetCode150}, question: other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deadlineForQuestion(tt.deadline, tt.question); !got.Equal(tt.want) {
+				t.Errorf("deadlineForQuestion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"letracker/internal/entity"
+	"letracker/internal/repository"
+	"letracker/pkg/srs"
+)
+
+// newTestService 以 fakeRepo 與固定時鐘建立 Service
+func newTestService(repo repository.Repository, now time.Time) *reviewServiceImpl {
+	return &reviewServiceImpl{repo: repo, clock: srs.FixedClock(now), importWake: make(chan struct{}, 1)}
+}
+
+// fakeRepo 測試用的記憶體 Repository
+// 內嵌 repository.Repository 介面：測試沒有用到的方法呼叫時會 panic，方便發現漏掉的依賴
+type fakeRepo struct {
+	repository.Repository
+
+	reviewsByDay map[string]int // Key: "2006-01-02"
+	countCalls   int
+	countErr     error
+
+	deadline  *entity.InterviewDeadline
+	algorithm string
+	params    *entity.SchedulerParams
+	ease      *entity.InitialEasePolicy
+
+	questions  map[string]*entity.Question         // Key: ID
+	slugErrs   map[string]error                    // GetQuestionBySlug 對這些 slug 回傳錯誤
+	stats      map[string]entity.UserQuestionStats // Key: QuestionID
+	logs       []entity.SubmissionLog
+	snapshots  []entity.ReviewSnapshot
+	cursor     *entity.SyncCursor
+	jobs       []*fakeJob
+	jobUpdates []entity.ImportJob         // 每次 UpdateImportJob 的內容 (依呼叫順序)
+	replaced   []entity.UserQuestionStats // BatchReplaceUserStats 寫入的狀態
+	userIDs    []string
+}
+
+func (r *fakeRepo) CountReviewsByDay(ctx context.Context, userID string, from, to time.Time) (map[string]int, error) {
+	r.countCalls++
+	if r.countErr != nil {
+		return nil, r.countErr
+	}
+	counts := make(map[string]int)
+	for day, n := range r.reviewsByDay {
+		at, _ := time.Parse(workloadDayLayout, day)
+		if !at.Before(from) && at.Before(to) {
+			counts[day] = n
+		}
+	}
+	return counts, nil
+}
+
+func (r *fakeRepo) GetInterviewDeadline(ctx context.Context, userID string) (*entity.InterviewDeadline, error) {
+	if r.deadline == nil {
+		return nil, nil
+	}
+	deadline := *r.deadline
+	return &deadline, nil
+}
+
+func (r *fakeRepo) SaveInterviewDeadline(ctx context.Context, userID string, deadline *entity.InterviewDeadline) error {
+	r.deadline = deadline
+	return nil
+}
+
+func (r *fakeRepo) GetUserAlgorithm(ctx context.Context, userID string) (string, error) {
+	return r.algorithm, nil
+}
+
+func (r *fakeRepo) GetSchedulerParams(ctx context.Context, userID string) (*entity.SchedulerParams, error) {
+	return r.params, nil
+}
+
+func (r *fakeRepo) GetInitialEasePolicy(ctx context.Context, userID string) (*entity.InitialEasePolicy, error) {
+	return r.ease, nil
+}
+
+// 以下為匯入/重算用的記憶體儲存 (只有一位使用者，userID 一律忽略)
+
+// fakeJob 排隊中的背景工作與它的 payload
+type fakeJob struct {
+	job     entity.ImportJob
+	payload []byte
+}
+
+func (r *fakeRepo) GetQuestionBySlug(ctx context.Context, slug string) (*entity.Question, error) {
+	if err := r.slugErrs[slug]; err != nil {
+		return nil, err
+	}
+	for _, q := range r.questions {
+		if q.Slug == slug {
+			question := *q
+			return &question, nil
+		}
+	}
+	return nil, repository.ErrQuestionNotFound
+}
+
+func (r *fakeRepo) GetQuestionByID(ctx context.Context, id string) (*entity.Question, error) {
+	q, ok := r.questions[id]
+	if !ok {
+		return nil, repository.ErrQuestionNotFound
+	}
+	question := *q
+	return &question, nil
+}
+
+func (r *fakeRepo) CreateQuestion(ctx context.Context, q entity.Question) (string, error) {
+	if r.questions == nil {
+		r.questions = make(map[string]*entity.Question)
+	}
+	q.ID = fmt.Sprintf("q-%d", len(r.questions)+1)
+	r.questions[q.ID] = &q
+	return q.ID, nil
+}
+
+func (r *fakeRepo) UpdateQuestionMeta(ctx context.Context, id, difficulty, category string) error {
+	q := r.questions[id]
+	if difficulty != "" {
+		q.Difficulty = difficulty
+	}
+	if category != "" {
+		q.Category = category
+	}
+	return nil
+}
+
+func (r *fakeRepo) LockUser(ctx context.Context, userID string) (func(), error) {
+	return func() {}, nil
+}
+
+func (r *fakeRepo) GetUserStats(ctx context.Context, userID, questionID string) (*entity.UserQuestionStats, error) {
+	stats, ok := r.stats[questionID]
+	if !ok {
+		return nil, nil
+	}
+	return &stats, nil
+}
+
+func (r *fakeRepo) UpsertUserStats(ctx context.Context, stats entity.UserQuestionStats) error {
+	if r.stats == nil {
+		r.stats = make(map[string]entity.UserQuestionStats)
+	}
+	r.stats[stats.QuestionID] = stats
+	return nil
+}
+
+func (r *fakeRepo) ListUserStats(ctx context.Context, userID string) ([]entity.UserQuestionStats, error) {
+	all := make([]entity.UserQuestionStats, 0, len(r.stats))
+	for _, stats := range r.stats {
+		all = append(all, stats)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].QuestionID < all[j].QuestionID })
+	return all, nil
+}
+
+func (r *fakeRepo) UpsertUserStatsWithRelated(ctx context.Context, stats entity.UserQuestionStats, related []entity.RelatedAdjustment) error {
+	return r.UpsertUserStats(ctx, stats)
+}
+
+func (r *fakeRepo) BatchReplaceUserStats(ctx context.Context, stats []entity.UserQuestionStats, snapshots []entity.ReviewSnapshot) error {
+	for _, s := range stats {
+		r.UpsertUserStats(ctx, s)
+	}
+	r.replaced = append(r.replaced, stats...)
+	return nil
+}
+
+func (r *fakeRepo) ListRelatedStats(ctx context.Context, userID, questionID string) ([]entity.RelatedStats, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) ListCardEvents(ctx context.Context, userID, questionID string) ([]entity.CardEvent, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) ListUserCardEvents(ctx context.Context, userID string) ([]entity.CardEvent, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) CreateLog(ctx context.Context, log entity.SubmissionLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *fakeRepo) BatchCreateLogs(ctx context.Context, logs []entity.SubmissionLog) error {
+	r.logs = append(r.logs, logs...)
+	return nil
+}
+
+func (r *fakeRepo) ListExternalIDs(ctx context.Context, userID string) (map[int64]bool, error) {
+	ids := make(map[int64]bool)
+	for _, log := range r.logs {
+		if log.ExternalID != 0 {
+			ids[log.ExternalID] = true
+		}
+	}
+	return ids, nil
+}
+
+func (r *fakeRepo) GetUserLogs(ctx context.Context, userID string) ([]entity.SubmissionLog, error) {
+	logs := slices.Clone(r.logs)
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].Date.Before(logs[j].Date) })
+	return logs, nil
+}
+
+func (r *fakeRepo) GetQuestionLogs(ctx context.Context, userID, questionID string) ([]entity.SubmissionLog, error) {
+	logs, _ := r.GetUserLogs(ctx, userID)
+	return slices.DeleteFunc(logs, func(log entity.SubmissionLog) bool { return log.QuestionID != questionID }), nil
+}
+
+func (r *fakeRepo) SaveSnapshots(ctx context.Context, snapshots []entity.ReviewSnapshot) error {
+	r.snapshots = append(r.snapshots, snapshots...)
+	return nil
+}
+
+func (r *fakeRepo) SaveSyncCursor(ctx context.Context, cursor entity.SyncCursor) error {
+	r.cursor = &cursor
+	return nil
+}
+
+func (r *fakeRepo) ListUserIDs(ctx context.Context) ([]string, error) {
+	return r.userIDs, nil
+}
+
+func (r *fakeRepo) CreateImportJob(ctx context.Context, job entity.ImportJob, payload []byte) (string, error) {
+	job.ID = fmt.Sprintf("job-%d", len(r.jobs)+1)
+	job.Status = entity.ImportJobQueued
+	r.jobs = append(r.jobs, &fakeJob{job: job, payload: payload})
+	return job.ID, nil
+}
+
+func (r *fakeRepo) ClaimImportJob(ctx context.Context) (*entity.ImportJob, []byte, error) {
+	for _, j := range r.jobs {
+		if j.job.Status == entity.ImportJobQueued {
+			j.job.Status = entity.ImportJobRunning
+			job := j.job
+			return &job, j.payload, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+func (r *fakeRepo) UpdateImportJob(ctx context.Context, job entity.ImportJob) error {
+	job.Slugs = slices.Clone(job.Slugs)
+	r.jobUpdates = append(r.jobUpdates, job)
+	for _, j := range r.jobs {
+		if j.job.ID == job.ID {
+			j.job = job
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepo) GetImportJob(ctx context.Context, userID, jobID string) (*entity.ImportJob, error) {
+	for _, j := range r.jobs {
+		if j.job.ID == jobID {
+			job := j.job
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+// 沒有儲存過的設定一律回傳 nil (由 Service 套用預設值)
+
+func (r *fakeRepo) GetSchedulerConfig(ctx context.Context, userID string) (*entity.SchedulerConfig, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetStatusGradeMapping(ctx context.Context, userID string) (*entity.StatusGradeMapping, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetSessionWindow(ctx context.Context, userID string) (*int, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetGradingPolicy(ctx context.Context, userID string) (*entity.GradingPolicy, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetLeechPolicy(ctx context.Context, userID string) (*entity.LeechPolicy, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) GetRelatedCreditPolicy(ctx context.Context, userID string) (*entity.RelatedCreditPolicy, error) {
+	return nil, nil
+}
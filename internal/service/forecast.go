@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"letracker/internal/entity"
+	"letracker/pkg/srs"
+)
+
+// ForecastRequest 預測設定
+type ForecastRequest struct {
+	Days      int // 30 / 90 / 180 ...
+	NewPerDay int // 每天打算新增幾題
+	Runs      int // Monte Carlo 次數 (預設 200)
+}
+
+// Forecast 以使用者目前的所有題目狀態，加上歷史評分分佈，模擬未來的複習量
+func (s *reviewServiceImpl) Forecast(ctx context.Context, userID string, req ForecastRequest) (*srs.Forecast, error) {
+	config, err := s.GetSchedulerConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	scheduler, err := s.schedulerFor(ctx, userID, toSrsConfig(config))
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 目前所有題目的狀態 (NEW 的題目還沒排程、SUSPENDED 不會出現，都不列入)
+	stats, err := s.repo.ListUserStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := make([]srs.CardState, 0, len(stats))
+	for _, st := range stats {
+		if st.Status == "NEW" || st.Status == "SUSPENDED" || st.LastReviewedAt.IsZero() {
+			continue
+		}
+		cards = append(cards, cardStateOf(st))
+	}
+
+	// 2. 從歷史紀錄統計評分分佈與平均花費時間 (與回放相同，以一次練習為單位)
+	logs, err := s.repo.GetUserLogs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := s.GetStatusGradeMapping(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	windowMinutes, err := s.GetSessionWindow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	grading, err := s.GetGradingPolicy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	dist, secondsPerGrade := gradeProfile(logs, statuses, time.Duration(windowMinutes)*time.Minute, grading.HardAfterWrongAttempts)
+
+	runs := req.Runs
+	if runs <= 0 {
+		runs = 200
+	}
+
+	forecast := srs.Simulate(scheduler, cards, dist, srs.ForecastOptions{
+		Start:           s.clock.Now(),
+		Days:            req.Days,
+		Runs:            runs,
+		NewPerDay:       req.NewPerDay,
+		Seed:            1, // 固定種子：同樣的資料得到同樣的預測
+		SecondsPerGrade: secondsPerGrade,
+		Config:          toSrsConfig(config),
+	})
+	return &forecast, nil
+}
+
+// Helper: 統計評分分佈，以及每種評分平均花費的秒數 (沒有紀錄的用預設值)
+// 模擬的每次複習對應一次練習，所以先依題目把 Logs 合併成練習 (logs 需按時間排序)，
+// 再以整次練習的評分計數，花費時間為這次練習所有提交的總和
+func gradeProfile(logs []entity.SubmissionLog, statuses entity.StatusGradeMapping, window time.Duration, hardAfter int) (srs.GradeDistribution, [4]float64) {
+	byQuestion := make(map[string][]entity.SubmissionLog)
+	for _, log := range logs {
+		byQuestion[log.QuestionID] = append(byQuestion[log.QuestionID], log)
+	}
+
+	var counts srs.GradeDistribution
+	var seconds, timed [4]float64
+
+	for _, questionLogs := range byQuestion {
+		for _, session := range collapseSessions(itemsFromLogs(questionLogs, statuses), window, hardAfter) {
+			if session.grade < 0 || session.grade > 3 {
+				continue
+			}
+			counts[session.grade]++
+
+			taken := 0
+			for _, item := range session.items {
+				taken += item.TimeTakenSeconds
+			}
+			if taken > 0 {
+				seconds[session.grade] += float64(taken)
+				timed[session.grade]++
+			}
+		}
+	}
+
+	if counts == (srs.GradeDistribution{}) {
+		counts = srs.DefaultGradeDistribution
+	}
+
+	secondsPerGrade := srs.DefaultSecondsPerGrade
+	for grade := range secondsPerGrade {
+		if timed[grade] > 0 {
+			secondsPerGrade[grade] = seconds[grade] / timed[grade]
+		}
+	}
+
+	return counts, secondsPerGrade
+}
+
+// Helper: 把 DB 的狀態轉成 srs 的 CardState
+func cardStateOf(stats entity.UserQuestionStats) srs.CardState {
+	return srs.CardState{
+		IntervalDays:    stats.IntervalDays,
+		IntervalMinutes: stats.IntervalMinutes,
+		EaseFactor:      stats.EaseFactor,
+		Repetitions:     stats.Streak,
+		Stability:       stats.Stability,
+		Difficulty:      stats.Difficulty,
+		Phase:           phaseOf(&stats),
+		Step:            stats.LearningStep,
+		ReviewCount:     stats.ReviewCount,
+		NextReviewAt:    stats.NextReviewAt,
+		LastReviewedAt:  stats.LastReviewedAt,
+	}
+}
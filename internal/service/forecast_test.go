@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"letracker/internal/entity"
+	"letracker/pkg/srs"
+)
+
+func TestGradeProfile(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	// q1 的 WA WA AC 是同一次練習 (中間穿插 q2 的提交也不影響)，q2 一次 AC，q3 是即時練習
+	logs := []entity.SubmissionLog{
+		{QuestionID: "q1", ExternalID: 1, Date: at(0), SubmissionStatus: "Wrong Answer", TimeTakenSeconds: 60},
+		{QuestionID: "q2", ExternalID: 2, Date: at(5), SubmissionStatus: "Accepted", MasteryLevel: 2, TimeTakenSeconds: 100},
+		{QuestionID: "q1", ExternalID: 3, Date: at(10), SubmissionStatus: "Wrong Answer", TimeTakenSeconds: 60},
+		{QuestionID: "q1", ExternalID: 4, Date: at(20), SubmissionStatus: "Accepted", MasteryLevel: 2, TimeTakenSeconds: 120},
+		{QuestionID: "q3", Date: at(30), MasteryLevel: 1, TimeTakenSeconds: 300},
+	}
+
+	dist, seconds := gradeProfile(logs, DefaultStatusGradeMapping(), time.Hour, 3)
+	if want := (srs.GradeDistribution{0, 1, 1, 1}); dist != want {
+		t.Errorf("distribution = %v, want %v (one count per session)", dist, want)
+	}
+	if want := [4]float64{srs.DefaultSecondsPerGrade[0], 300, 240, 100}; seconds != want {
+		t.Errorf("seconds per grade = %v, want %v (whole session)", seconds, want)
+	}
+
+	// 時間窗為 0：每次提交各自計數
+	dist, _ = gradeProfile(logs, DefaultStatusGradeMapping(), 0, 3)
+	if want := (srs.GradeDistribution{2, 1, 2, 0}); dist != want {
+		t.Errorf("distribution without a window = %v, want %v", dist, want)
+	}
+
+	dist, seconds = gradeProfile(nil, DefaultStatusGradeMapping(), time.Hour, 3)
+	if dist != srs.DefaultGradeDistribution || seconds != srs.DefaultSecondsPerGrade {
+		t.Errorf("empty history = %v, %v, want the defaults", dist, seconds)
+	}
+}
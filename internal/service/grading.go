@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"letracker/internal/entity"
+)
+
+// ErrInvalidGradingPolicy 評分門檻設定不合理
+var ErrInvalidGradingPolicy = errors.New("invalid grading policy")
+
+// GradingSignals 練習時的原始訊號，讓 Server 自動推導 0-3 的評分
+type GradingSignals struct {
+	TimeTakenSeconds int  // 花了多久 (0 代表沒有記錄)
+	WrongAttempts    int  // AC 之前錯了幾次
+	UsedHints        bool // 有沒有看提示/題解
+	Solved           bool // 最後有沒有 AC
+}
+
+// DefaultGradingPolicy 預設門檻 (分鐘)：越難的題目允許越久
+func DefaultGradingPolicy() entity.GradingPolicy {
+	return entity.GradingPolicy{
+		Thresholds: map[string]entity.GradeThresholds{
+			"Easy":   {EasyMinutes: 10, GoodMinutes: 20},
+			"Medium": {EasyMinutes: 20, GoodMinutes: 40},
+			"Hard":   {EasyMinutes: 35, GoodMinutes: 60},
+		},
+		HardAfterWrongAttempts: 3,
+		HintsCapGrade:          1,
+	}
+}
+
+// deriveGrade 依訊號推導評分：
+//  1. 沒有 AC -> Again
+//  2. 依花費時間與題目難度的門檻：Easy / Good / Hard (沒有時間紀錄視為 Good)
+//  3. 錯過 1 次以上最多 Good；錯到 HardAfterWrongAttempts 次以上最多 Hard
+//  4. 看了提示最多 HintsCapGrade
+func deriveGrade(policy entity.GradingPolicy, difficulty string, sig GradingSignals) int {
+	if !sig.Solved {
+		return 0
+	}
+
+	// 難度不明的題目以 Medium 的門檻計算 (GetGradingPolicy 保證三種難度都有值)
+	thresholds, ok := policy.Thresholds[difficulty]
+	if !ok {
+		thresholds = policy.Thresholds["Medium"]
+	}
+
+	grade := 2
+	if sig.TimeTakenSeconds > 0 {
+		minutes := float64(sig.TimeTakenSeconds) / 60
+		switch {
+		case minutes <= float64(thresholds.EasyMinutes):
+			grade = 3
+		case minutes <= float64(thresholds.GoodMinutes):
+			grade = 2
+		default:
+			grade = 1
+		}
+	}
+
+	if sig.WrongAttempts > 0 {
+		grade = min(grade, 2)
+	}
+	if policy.HardAfterWrongAttempts > 0 && sig.WrongAttempts >= policy.HardAfterWrongAttempts {
+		grade = min(grade, 1)
+	}
+	if sig.UsedHints {
+		grade = min(grade, policy.HintsCapGrade)
+	}
+
+	return max(grade, 1)
+}
+
+// validateGradingPolicy 檢查使用者的設定 (thresholds 只需要給想覆寫的難度)
+func validateGradingPolicy(policy entity.GradingPolicy) error {
+	defaults := DefaultGradingPolicy()
+	for difficulty, t := range policy.Thresholds {
+		if _, ok := defaults.Thresholds[difficulty]; !ok {
+			return fmt.Errorf("%w: unknown difficulty %q (expected Easy, Medium or Hard)", ErrInvalidGradingPolicy, difficulty)
+		}
+		if t.EasyMinutes <= 0 || t.GoodMinutes <= 0 {
+			return fmt.Errorf("%w: %s thresholds must be positive", ErrInvalidGradingPolicy, difficulty)
+		}
+		if t.EasyMinutes > t.GoodMinutes {
+			return fmt.Errorf("%w: %s easy_minutes must not exceed good_minutes", ErrInvalidGradingPolicy, difficulty)
+		}
+	}
+	if policy.HardAfterWrongAttempts < 0 {
+		return fmt.Errorf("%w: hard_after_wrong_attempts must not be negative", ErrInvalidGradingPolicy)
+	}
+	if policy.HintsCapGrade < 1 || policy.HintsCapGrade > 3 {
+		return fmt.Errorf("%w: hints_cap_grade must be between 1 and 3", ErrInvalidGradingPolicy)
+	}
+	return nil
+}
+
+// GetGradingPolicy 回傳使用者的設定，門檻疊加在預設值上 (沒給的難度沿用預設)
+func (s *reviewServiceImpl) GetGradingPolicy(ctx context.Context, userID string) (entity.GradingPolicy, error) {
+	policy := DefaultGradingPolicy()
+
+	saved, err := s.repo.GetGradingPolicy(ctx, userID)
+	if err != nil {
+		return entity.GradingPolicy{}, err
+	}
+	if saved != nil {
+		maps.Copy(policy.Thresholds, saved.Thresholds)
+		policy.HardAfterWrongAttempts = saved.HardAfterWrongAttempts
+		policy.HintsCapGrade = saved.HintsCapGrade
+	}
+	return policy, nil
+}
+
+// SetGradingPolicy 儲存使用者的設定，回傳疊加預設值後的完整設定
+func (s *reviewServiceImpl) SetGradingPolicy(ctx context.Context, userID string, policy entity.GradingPolicy) (entity.GradingPolicy, error) {
+	if err := validateGradingPolicy(policy); err != nil {
+		return entity.GradingPolicy{}, err
+	}
+	policy.UserID = userID
+	if err := s.repo.SaveGradingPolicy(ctx, policy); err != nil {
+		return entity.GradingPolicy{}, err
+	}
+	return s.GetGradingPolicy(ctx, userID)
+}
+
+// Helper: 依使用者的門檻與題目難度推導評分
+func (s *reviewServiceImpl) gradeFromSignals(ctx context.Context, userID, questionID string, sig GradingSignals) (int, error) {
+	policy, err := s.GetGradingPolicy(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	q, err := s.repo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return deriveGrade(policy, q.Difficulty, sig), nil
+}
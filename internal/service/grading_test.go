@@ -0,0 +1,47 @@
+package service
+
+import "testing"
+
+func TestDeriveGrade(t *testing.T) {
+	policy := DefaultGradingPolicy()
+	minutes := func(m int) int { return m * 60 }
+
+	tests := []struct {
+		name       string
+		difficulty string
+		sig        GradingSignals
+		want       int
+	}{
+		{name: "not solved", difficulty: "Easy", sig: GradingSignals{TimeTakenSeconds: minutes(5)}, want: 0},
+		{name: "fast", difficulty: "Medium", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(15)}, want: 3},
+		{name: "on the easy threshold", difficulty: "Medium", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(20)}, want: 3},
+		{name: "within the good threshold", difficulty: "Medium", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(30)}, want: 2},
+		{name: "slow", difficulty: "Medium", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(50)}, want: 1},
+		{name: "hard questions allow more time", difficulty: "Hard", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(30)}, want: 3},
+		{name: "easy questions allow less time", difficulty: "Easy", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(30)}, want: 1},
+		{name: "unknown difficulty uses Medium", difficulty: "", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(30)}, want: 2},
+		{name: "no time recorded", difficulty: "Medium", sig: GradingSignals{Solved: true}, want: 2},
+		{name: "one wrong attempt caps at Good", difficulty: "Medium", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(5), WrongAttempts: 1}, want: 2},
+		{name: "many wrong attempts cap at Hard", difficulty: "Medium", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(5), WrongAttempts: 3}, want: 1},
+		{name: "hints cap the grade", difficulty: "Medium", sig: GradingSignals{Solved: true, TimeTakenSeconds: minutes(5), UsedHints: true}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deriveGrade(policy, tt.difficulty, tt.sig); got != tt.want {
+				t.Errorf("deriveGrade() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveGradeSolvedIsNeverAgain(t *testing.T) {
+	policy := DefaultGradingPolicy()
+	policy.HintsCapGrade = 0
+
+	// 有 AC 就不會是 Again，即使提示上限設成 0
+	sig := GradingSignals{Solved: true, UsedHints: true}
+	if got := deriveGrade(policy, "Medium", sig); got != 1 {
+		t.Errorf("deriveGrade() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+// ErrImportJobNotFound 匯入工作不存在 (或不屬於這位使用者)
+var ErrImportJobNotFound = errors.New("import job not found")
+
+// importPollInterval Worker 沒被喚醒時，多久檢查一次有沒有排隊中的工作
+const importPollInterval = 5 * time.Second
+
+// SubmitImport 建立背景匯入工作並立即回傳，實際的回放交給 StartImportWorkers 啟動的 Worker
+func (s *reviewServiceImpl) SubmitImport(ctx context.Context, userID string, req ImportSubmissionRequest) (*entity.ImportJob, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make(map[string]bool)
+	for _, item := range req.History {
+		slugs[item.Slug] = true
+	}
+
+	job := entity.ImportJob{
+		UserID:       userID,
+		Kind:         entity.JobKindImport,
+		Status:       entity.ImportJobQueued,
+		TotalRecords: len(req.History),
+		TotalSlugs:   len(slugs),
+		CreatedAt:    s.clock.Now(),
+	}
+	job.ID, err = s.repo.CreateImportJob(ctx, job, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	s.wakeWorker()
+	return &job, nil
+}
+
+// wakeWorker 叫醒一個閒置的 Worker (都在忙的話，下一個做完的 Worker 會接手)
+func (s *reviewServiceImpl) wakeWorker() {
+	select {
+	case s.importWake <- struct{}{}:
+	default:
+	}
+}
+
+// GetImportJob 取得匯入工作的狀態與每題的進度
+func (s *reviewServiceImpl) GetImportJob(ctx context.Context, userID, jobID string) (*entity.ImportJob, error) {
+	job, err := s.repo.GetImportJob(ctx, userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+// StartImportWorkers 啟動 workers 個背景 Worker 處理匯入工作，ctx 結束時停止
+// 啟動時會把上次停在 running 的工作放回佇列 (假設只有一個 Server 在處理匯入)
+func (s *reviewServiceImpl) StartImportWorkers(ctx context.Context, workers int) {
+	if n, err := s.repo.RequeueRunningImportJobs(ctx); err != nil {
+		log.Printf("import: failed to requeue running jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("import: requeued %d interrupted jobs", n)
+	}
+
+	for i := 0; i < workers; i++ {
+		go s.importWorker(ctx)
+	}
+}
+
+func (s *reviewServiceImpl) importWorker(ctx context.Context) {
+	ticker := time.NewTicker(importPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// 一次把排隊中的工作做完
+		for s.runNextImportJob(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.importWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNextImportJob 處理一個排隊中的工作，沒有工作時回傳 false
+func (s *reviewServiceImpl) runNextImportJob(ctx context.Context) bool {
+	job, payload, err := s.repo.ClaimImportJob(ctx)
+	if err != nil {
+		log.Printf("import: failed to claim job: %v", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	if job.Kind == entity.JobKindRecompute {
+		s.runRecomputeJob(ctx, job, payload)
+	} else {
+		s.runImportJob(ctx, job, payload)
+	}
+	return true
+}
+
+// runImportJob 執行匯入，每處理完一題就更新一次進度
+func (s *reviewServiceImpl) runImportJob(ctx context.Context, job *entity.ImportJob, payload []byte) {
+	// 重新排隊的工作從頭開始 (已經寫入的紀錄會因為 submission id 被跳過)
+	job.Slugs = nil
+	job.ProcessedSlugs, job.FailedSlugs, job.Imported = 0, 0, 0
+
+	var req ImportSubmissionRequest
+	err := json.Unmarshal(payload, &req)
+	if err == nil {
+		var result *ImportResult
+		result, err = s.importHistory(ctx, job.UserID, req, func(slug entity.ImportSlugResult) {
+			job.ProcessedSlugs++
+			job.Imported += slug.Imported
+			if slug.Error != "" {
+				job.FailedSlugs++
+			}
+			job.Slugs = append(job.Slugs, slug)
+			s.saveImportJob(ctx, *job)
+		})
+		if err == nil {
+			job.Skipped = result.Skipped
+			job.Ignored = result.Ignored
+		}
+	}
+
+	s.finishJob(ctx, job, err)
+}
+
+// finishJob 依執行結果把工作標記為 done 或 failed
+func (s *reviewServiceImpl) finishJob(ctx context.Context, job *entity.ImportJob, err error) {
+	job.Status = entity.ImportJobDone
+	if err != nil {
+		job.Status = entity.ImportJobFailed
+		job.Error = err.Error()
+	}
+	finishedAt := s.clock.Now()
+	job.FinishedAt = &finishedAt
+	s.saveImportJob(ctx, *job)
+}
+
+// saveImportJob 進度寫入失敗不影響匯入本身，只記錄下來
+func (s *reviewServiceImpl) saveImportJob(ctx context.Context, job entity.ImportJob) {
+	if err := s.repo.UpdateImportJob(ctx, job); err != nil {
+		log.Printf("import: failed to update job %s: %v", job.ID, err)
+	}
+}
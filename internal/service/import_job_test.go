@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+// importRequest 兩題、三筆提交的匯入內容
+func importRequest(start time.Time) ImportSubmissionRequest {
+	return ImportSubmissionRequest{History: []HistoryItem{
+		{Title: "Two Sum", Slug: "two-sum", Status: "Accepted", Timestamp: start.Unix(), SubmissionID: 1},
+		{Title: "Two Sum", Slug: "two-sum", Status: "Accepted", Timestamp: start.AddDate(0, 0, 3).Unix(), SubmissionID: 3},
+		{Title: "Valid Anagram", Slug: "valid-anagram", Status: "Wrong Answer", Timestamp: start.Add(time.Hour).Unix(), SubmissionID: 2},
+	}}
+}
+
+func TestImportJobLifecycle(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	repo := &fakeRepo{}
+	svc := newTestService(repo, now)
+
+	submitted, err := svc.SubmitImport(ctx, "u", importRequest(start))
+	if err != nil {
+		t.Fatalf("SubmitImport() error = %v", err)
+	}
+	if submitted.Status != entity.ImportJobQueued || submitted.TotalRecords != 3 || submitted.TotalSlugs != 2 || !submitted.CreatedAt.Equal(now) {
+		t.Errorf("submitted job = %+v, want queued with 3 records in 2 slugs", *submitted)
+	}
+	if len(repo.logs) != 0 {
+		t.Error("SubmitImport should only queue the job, not import")
+	}
+	select {
+	case <-svc.importWake:
+	default:
+		t.Error("SubmitImport should wake a worker")
+	}
+
+	if !svc.runNextImportJob(ctx) {
+		t.Fatal("runNextImportJob() = false, want the queued job to run")
+	}
+	if svc.runNextImportJob(ctx) {
+		t.Error("runNextImportJob() = true with an empty queue")
+	}
+
+	// 每處理完一題更新一次進度，最後再標記完成
+	if len(repo.jobUpdates) != 3 {
+		t.Fatalf("got %d job updates, want 3", len(repo.jobUpdates))
+	}
+	first := repo.jobUpdates[0]
+	if first.Status != entity.ImportJobRunning || first.ProcessedSlugs != 1 || len(first.Slugs) != 1 {
+		t.Errorf("first progress update = %+v, want running with 1 processed slug", first)
+	}
+
+	job, err := svc.GetImportJob(ctx, "u", submitted.ID)
+	if err != nil {
+		t.Fatalf("GetImportJob() error = %v", err)
+	}
+	if job.Status != entity.ImportJobDone || job.ProcessedSlugs != 2 || job.FailedSlugs != 0 || job.Imported != 3 {
+		t.Errorf("finished job = %+v, want done with 3 records imported", *job)
+	}
+	if job.FinishedAt == nil || !job.FinishedAt.Equal(now) {
+		t.Errorf("FinishedAt = %v, want %v", job.FinishedAt, now)
+	}
+	if len(repo.logs) != 3 || len(repo.stats) != 2 {
+		t.Errorf("wrote %d logs and %d stats, want 3 and 2", len(repo.logs), len(repo.stats))
+	}
+	if repo.cursor == nil || repo.cursor.LastSubmissionID != 3 {
+		t.Errorf("sync cursor = %+v, want LastSubmissionID 3", repo.cursor)
+	}
+
+	// 同一包再送一次：全部跳過，不會重複寫入
+	again, _ := svc.SubmitImport(ctx, "u", importRequest(start))
+	svc.runNextImportJob(ctx)
+	job, _ = svc.GetImportJob(ctx, "u", again.ID)
+	if job.Status != entity.ImportJobDone || job.Imported != 0 || job.Skipped != 3 || len(repo.logs) != 3 {
+		t.Errorf("resubmitted job = %+v with %d logs, want everything skipped", *job, len(repo.logs))
+	}
+}
+
+func TestImportJobFailedSlug(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	repo := &fakeRepo{slugErrs: map[string]error{"valid-anagram": errors.New("db down")}}
+	svc := newTestService(repo, now)
+
+	submitted, _ := svc.SubmitImport(ctx, "u", importRequest(time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC)))
+	svc.runNextImportJob(ctx)
+
+	// 單題失敗不會中斷整批匯入，但不推進同步游標
+	job, _ := svc.GetImportJob(ctx, "u", submitted.ID)
+	if job.Status != entity.ImportJobDone || job.ProcessedSlugs != 2 || job.FailedSlugs != 1 || job.Imported != 2 {
+		t.Errorf("job = %+v, want done with one failed slug", *job)
+	}
+	for _, slug := range job.Slugs {
+		if failed := slug.Slug == "valid-anagram"; failed != (slug.Error != "") {
+			t.Errorf("slug %s Error = %q", slug.Slug, slug.Error)
+		}
+	}
+	if repo.cursor != nil {
+		t.Errorf("sync cursor = %+v, want it left alone after a failure", repo.cursor)
+	}
+}
+
+func TestImportJobBadPayload(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	repo := &fakeRepo{}
+	svc := newTestService(repo, now)
+
+	id, _ := repo.CreateImportJob(ctx, entity.ImportJob{UserID: "u", Kind: entity.JobKindImport}, []byte("not json"))
+	svc.runNextImportJob(ctx)
+
+	job, _ := svc.GetImportJob(ctx, "u", id)
+	if job.Status != entity.ImportJobFailed || job.Error == "" || job.FinishedAt == nil {
+		t.Errorf("job = %+v, want failed with an error", *job)
+	}
+}
+
+func TestGetImportJobNotFound(t *testing.T) {
+	svc := newTestService(&fakeRepo{}, time.Now())
+	if _, err := svc.GetImportJob(context.Background(), "u", "missing"); !errors.Is(err, ErrImportJobNotFound) {
+		t.Errorf("GetImportJob() error = %v, want ErrImportJobNotFound", err)
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+
+	"letracker/internal/entity"
+)
+
+// ImportPreview dry run 的結果：匯入之後會新增什麼、每題的狀態會怎麼變
+type ImportPreview struct {
+	NewQuestions int // 會新建幾題
+	NewLogs      int // 會寫入幾筆 Log
+	Skipped      int // 之前已經匯入過的紀錄
+	Ignored      int // 依狀態對應表略過的紀錄
+	Questions    []ImportDiff
+}
+
+// ImportDiff 單題匯入前後的狀態
+type ImportDiff struct {
+	Slug        string
+	QuestionID  string // 新題目為空字串
+	NewQuestion bool
+	NewLogs     int
+	Before      *entity.UserQuestionStats // nil 代表還沒有狀態
+	After       entity.UserQuestionStats
+}
+
+// PreviewImport 與 ImportHistory 走同樣的分組與回放，但不寫入任何東西
+// 全部都匯入過的題目不會被回放，所以不列在 Questions 裡
+// 新題目還沒有 ID，Fuzz 的結果可能與實際匯入差一兩天
+func (s *reviewServiceImpl) PreviewImport(ctx context.Context, userID string, req ImportSubmissionRequest) (*ImportPreview, error) {
+	session, err := s.newImportSession(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := groupHistory(req.History, session.seen, session.statuses)
+	preview := &ImportPreview{Skipped: batch.skipped, Ignored: batch.ignored}
+
+	for _, slug := range batch.slugs {
+		if batch.fresh[slug] == 0 {
+			continue
+		}
+		items := batch.items[slug]
+
+		question, isNew, err := s.lookupQuestion(ctx, slug, items)
+		if err != nil {
+			return nil, err
+		}
+		var current *entity.UserQuestionStats
+		var existing []entity.SubmissionLog
+		var events []entity.CardEvent
+		if !isNew {
+			current, err = s.repo.GetUserStats(ctx, userID, question.ID)
+			if err != nil {
+				return nil, err
+			}
+			existing, err = s.repo.GetQuestionLogs(ctx, userID, question.ID)
+			if err != nil {
+				return nil, err
+			}
+			events, err = s.repo.ListCardEvents(ctx, userID, question.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		replay := s.replaySlug(session, question, current, existing, events, items)
+		if err := session.workload.err(); err != nil {
+			return nil, err
+		}
+		preview.Skipped += replay.duplicates
+
+		if isNew {
+			preview.NewQuestions++
+		}
+		preview.NewLogs += len(replay.logs)
+		preview.Questions = append(preview.Questions, ImportDiff{
+			Slug:        slug,
+			QuestionID:  question.ID,
+			NewQuestion: isNew,
+			NewLogs:     len(replay.logs),
+			Before:      current,
+			After:       replay.final,
+		})
+	}
+
+	return preview, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPreviewImportDoesNotWrite(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{}
+	svc := newTestService(repo, now)
+
+	preview, err := svc.PreviewImport(context.Background(), "u", importRequest(time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("PreviewImport() error = %v", err)
+	}
+	if preview.NewQuestions != 2 || preview.NewLogs != 3 || len(preview.Questions) != 2 {
+		t.Errorf("preview = %+v, want 2 new questions with 3 logs", *preview)
+	}
+	for _, diff := range preview.Questions {
+		if !diff.NewQuestion || diff.QuestionID != "" || diff.Before != nil {
+			t.Errorf("diff for %s = %+v, want a new question without an ID or previous state", diff.Slug, diff)
+		}
+	}
+	if len(repo.questions) != 0 || len(repo.stats) != 0 || len(repo.logs) != 0 || len(repo.snapshots) != 0 || repo.cursor != nil {
+		t.Error("PreviewImport should not write anything")
+	}
+}
+
+func TestPreviewImportMatchesImport(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	repo := &fakeRepo{}
+	svc := newTestService(repo, now)
+
+	if _, err := svc.ImportHistory(ctx, "u", importRequest(start)); err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+
+	// 已經存在的題目 (有 ID，Fuzz 相同)：預覽的結果與實際匯入一致
+	next := importRequest(start)
+	next.History = append(next.History, HistoryItem{Title: "Two Sum", Slug: "two-sum", Status: "Accepted", Timestamp: start.AddDate(0, 0, 12).Unix(), SubmissionID: 4})
+
+	preview, err := svc.PreviewImport(ctx, "u", next)
+	if err != nil {
+		t.Fatalf("PreviewImport() error = %v", err)
+	}
+	if preview.Skipped != 3 || preview.NewLogs != 1 || len(preview.Questions) != 1 {
+		t.Fatalf("preview = %+v, want one new log for two-sum", *preview)
+	}
+	diff := preview.Questions[0]
+	before := repo.stats[diff.QuestionID]
+	if diff.NewQuestion || diff.Before == nil || !reflect.DeepEqual(*diff.Before, before) {
+		t.Errorf("Before = %+v, want the current state %+v", diff.Before, before)
+	}
+
+	if _, err := svc.ImportHistory(ctx, "u", next); err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+	if after := repo.stats[diff.QuestionID]; !reflect.DeepEqual(diff.After, after) {
+		t.Errorf("preview After = %+v, import wrote %+v", diff.After, after)
+	}
+}
+
+func TestPreviewImportLookupError(t *testing.T) {
+	lookupErr := errors.New("db down")
+	repo := &fakeRepo{slugErrs: map[string]error{"two-sum": lookupErr}}
+	svc := newTestService(repo, time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+
+	// 沒找到以外的錯誤不能當成新題
+	if _, err := svc.PreviewImport(context.Background(), "u", importRequest(time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC))); !errors.Is(err, lookupErr) {
+		t.Errorf("PreviewImport() error = %v, want %v", err, lookupErr)
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+
+	"letracker/internal/entity"
+)
+
+// ErrInvalidInitialEasePolicy 新題初始 EF 設定不合理
+var ErrInvalidInitialEasePolicy = errors.New("invalid initial ease policy")
+
+// DefaultInitialEasePolicy 預設值：Easy 的題目 EF 高、第二次複習隔久一點，Hard 反之
+// 分類的調整量以 NeetCode 150 的分類為準，圖論與 DP 通常比較容易忘
+func DefaultInitialEasePolicy() entity.InitialEasePolicy {
+	return entity.InitialEasePolicy{
+		Difficulties: map[string]entity.DifficultyDefaults{
+			"Easy":   {EaseFactor: 2.7, IntervalScale: 1.25},
+			"Medium": {EaseFactor: 2.5, IntervalScale: 1.0},
+			"Hard":   {EaseFactor: 2.3, IntervalScale: 0.75},
+		},
+		Categories: map[string]float64{
+			"Backtracking":            -0.1,
+			"Graphs":                  -0.1,
+			"Advanced Graphs":         -0.2,
+			"1-D Dynamic Programming": -0.15,
+			"2-D Dynamic Programming": -0.2,
+		},
+	}
+}
+
+// questionSeed 新題的初始狀態
+type questionSeed struct {
+	EaseFactor      float64
+	SecondIntervals [3]int
+}
+
+// seedFor 依題目的難度與分類決定初始 EF 與第二次複習間隔
+// 沒有難度資料的題目視為 Medium
+func seedFor(policy entity.InitialEasePolicy, config entity.SchedulerConfig, q *entity.Question) questionSeed {
+	d, ok := policy.Difficulties[q.Difficulty]
+	if !ok {
+		d = policy.Difficulties["Medium"]
+	}
+	if d.EaseFactor == 0 {
+		d.EaseFactor = 2.5
+	}
+	if d.IntervalScale == 0 {
+		d.IntervalScale = 1
+	}
+
+	seed := questionSeed{
+		EaseFactor: math.Max(config.MinimumEF, d.EaseFactor+policy.Categories[q.Category]),
+	}
+	for i, days := range config.SecondIntervals {
+		seed.SecondIntervals[i] = max(1, int(math.Round(float64(days)*d.IntervalScale)))
+	}
+	return seed
+}
+
+// GetInitialEasePolicy 回傳預設值疊加使用者的覆寫
+func (s *reviewServiceImpl) GetInitialEasePolicy(ctx context.Context, userID string) (entity.InitialEasePolicy, error) {
+	policy := DefaultInitialEasePolicy()
+
+	overrides, err := s.repo.GetInitialEasePolicy(ctx, userID)
+	if err != nil {
+		return entity.InitialEasePolicy{}, err
+	}
+	if overrides != nil {
+		maps.Copy(policy.Difficulties, overrides.Difficulties)
+		maps.Copy(policy.Categories, overrides.Categories)
+	}
+	return policy, nil
+}
+
+// SetInitialEasePolicy 儲存使用者的覆寫 (只需要給想改的難度/分類)，回傳套用後的完整設定
+func (s *reviewServiceImpl) SetInitialEasePolicy(ctx context.Context, userID string, overrides entity.InitialEasePolicy) (entity.InitialEasePolicy, error) {
+	if err := validateInitialEasePolicy(overrides); err != nil {
+		return entity.InitialEasePolicy{}, err
+	}
+
+	overrides.UserID = userID
+	if err := s.repo.SaveInitialEasePolicy(ctx, overrides); err != nil {
+		return entity.InitialEasePolicy{}, err
+	}
+	return s.GetInitialEasePolicy(ctx, userID)
+}
+
+// seedForQuestion 讀取使用者設定並計算這題的初始狀態
+func (s *reviewServiceImpl) seedForQuestion(ctx context.Context, userID string, q *entity.Question) (questionSeed, error) {
+	policy, err := s.GetInitialEasePolicy(ctx, userID)
+	if err != nil {
+		return questionSeed{}, err
+	}
+	config, err := s.GetSchedulerConfig(ctx, userID)
+	if err != nil {
+		return questionSeed{}, err
+	}
+	return seedFor(policy, config, q), nil
+}
+
+func validateInitialEasePolicy(policy entity.InitialEasePolicy) error {
+	for difficulty, d := range policy.Difficulties {
+		if d.EaseFactor < 1.3 || d.EaseFactor > 3.5 {
+			return fmt.Errorf("%w: %s ease_factor must be between 1.3 and 3.5", ErrInvalidInitialEasePolicy, difficulty)
+		}
+		if d.IntervalScale < 0.25 || d.IntervalScale > 4 {
+			return fmt.Errorf("%w: %s interval_scale must be between 0.25 and 4", ErrInvalidInitialEasePolicy, difficulty)
+		}
+	}
+	for category, delta := range policy.Categories {
+		if delta < -1 || delta > 1 {
+			return fmt.Errorf("%w: %s adjustment must be between -1 and 1", ErrInvalidInitialEasePolicy, category)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+func TestSeedFor(t *testing.T) {
+	policy := DefaultInitialEasePolicy()
+	config := withConfigDefaults(entity.SchedulerConfig{}) // 第二次複習 3 / 5 / 7 天
+
+	tests := []struct {
+		name     string
+		policy   entity.InitialEasePolicy
+		config   entity.SchedulerConfig
+		question entity.Question
+		wantEF   float64
+		want2nd  [3]int
+	}{
+		{name: "Easy", policy: policy, config: config, question: entity.Question{Difficulty: "Easy", Category: "Arrays & Hashing"}, wantEF: 2.7, want2nd: [3]int{4, 6, 9}},
+		{name: "Medium", policy: policy, config: config, question: entity.Question{Difficulty: "Medium"}, wantEF: 2.5, want2nd: [3]int{3, 5, 7}},
+		{name: "Hard DP", policy: policy, config: config, question: entity.Question{Difficulty: "Hard", Category: "2-D Dynamic Programming"}, wantEF: 2.1, want2nd: [3]int{2, 4, 5}},
+		{name: "unknown difficulty is Medium", policy: policy, config: config, question: entity.Question{Category: "Graphs"}, wantEF: 2.4, want2nd: [3]int{3, 5, 7}},
+		{
+			name:     "never below the EF floor",
+			policy:   entity.InitialEasePolicy{Difficulties: map[string]entity.DifficultyDefaults{"Hard": {EaseFactor: 1.4, IntervalScale: 0.25}}, Categories: map[string]float64{"Graphs": -0.5}},
+			config:   config,
+			question: entity.Question{Difficulty: "Hard", Category: "Graphs"},
+			wantEF:   1.3,
+			want2nd:  [3]int{1, 1, 2},
+		},
+		{
+			name:     "empty policy falls back to the SM-2 defaults",
+			config:   config,
+			question: entity.Question{Difficulty: "Medium"},
+			wantEF:   2.5,
+			want2nd:  [3]int{3, 5, 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed := seedFor(tt.policy, tt.config, &tt.question)
+			if math.Abs(seed.EaseFactor-tt.wantEF) > 1e-9 {
+				t.Errorf("EaseFactor = %v, want %v", seed.EaseFactor, tt.wantEF)
+			}
+			if seed.SecondIntervals != tt.want2nd {
+				t.Errorf("SecondIntervals = %v, want %v", seed.SecondIntervals, tt.want2nd)
+			}
+		})
+	}
+}
+
+func TestGetInitialEasePolicyMergesOverrides(t *testing.T) {
+	repo := &fakeRepo{ease: &entity.InitialEasePolicy{
+		Difficulties: map[string]entity.DifficultyDefaults{"Hard": {EaseFactor: 2.0, IntervalScale: 0.5}},
+		Categories:   map[string]float64{"Trees": -0.05},
+	}}
+	svc := newTestService(repo, time.Now())
+
+	policy, err := svc.GetInitialEasePolicy(context.Background(), "u")
+	if err != nil {
+		t.Fatalf("GetInitialEasePolicy() error = %v", err)
+	}
+	if got := policy.Difficulties["Hard"]; got.EaseFactor != 2.0 || got.IntervalScale != 0.5 {
+		t.Errorf("Hard = %+v, want the override", got)
+	}
+	if got := policy.Difficulties["Easy"]; got != DefaultInitialEasePolicy().Difficulties["Easy"] {
+		t.Errorf("Easy = %+v, want the default", got)
+	}
+	if policy.Categories["Trees"] != -0.05 || policy.Categories["Graphs"] != -0.1 {
+		t.Errorf("Categories = %v, want the override merged over the defaults", policy.Categories)
+	}
+}
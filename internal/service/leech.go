@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+var (
+	// ErrInvalidLeechPolicy leech 設定不合理
+	ErrInvalidLeechPolicy = errors.New("invalid leech policy")
+	// ErrStatsNotFound 這題還沒有練習紀錄
+	ErrStatsNotFound = errors.New("no stats for this question")
+)
+
+// DefaultLeechPolicy lapse 8 次就暫停 (與 Anki 預設相同)
+func DefaultLeechPolicy() entity.LeechPolicy {
+	return entity.LeechPolicy{
+		Threshold: 8,
+		Action:    entity.LeechActionSuspend,
+	}
+}
+
+// applyLapse 累計 lapse，並在達到門檻時標記 leech
+// 第一次達到門檻之後，每再多 threshold/2 次 lapse 會再觸發一次 (解除暫停後又一直錯的情況)
+func applyLapse(stats *entity.UserQuestionStats, policy entity.LeechPolicy) {
+	stats.Lapses++
+	if policy.Threshold <= 0 || stats.Lapses < policy.Threshold {
+		return
+	}
+
+	repeat := max(policy.Threshold/2, 1)
+	if (stats.Lapses-policy.Threshold)%repeat != 0 {
+		return
+	}
+
+	stats.IsLeech = true
+	if policy.Action == entity.LeechActionSuspend {
+		stats.Status = "SUSPENDED"
+	}
+}
+
+func validateLeechPolicy(policy entity.LeechPolicy) error {
+	if policy.Threshold < 1 {
+		return fmt.Errorf("%w: threshold must be at least 1", ErrInvalidLeechPolicy)
+	}
+	if policy.Action != entity.LeechActionSuspend && policy.Action != entity.LeechActionTag {
+		return fmt.Errorf("%w: action must be %q or %q", ErrInvalidLeechPolicy, entity.LeechActionSuspend, entity.LeechActionTag)
+	}
+	return nil
+}
+
+func (s *reviewServiceImpl) GetLeechPolicy(ctx context.Context, userID string) (entity.LeechPolicy, error) {
+	policy, err := s.repo.GetLeechPolicy(ctx, userID)
+	if err != nil {
+		return entity.LeechPolicy{}, err
+	}
+	if policy == nil {
+		return DefaultLeechPolicy(), nil
+	}
+	return *policy, nil
+}
+
+func (s *reviewServiceImpl) SetLeechPolicy(ctx context.Context, userID string, policy entity.LeechPolicy) error {
+	if err := validateLeechPolicy(policy); err != nil {
+		return err
+	}
+	return s.repo.SaveLeechPolicy(ctx, userID, policy)
+}
+
+func (s *reviewServiceImpl) ListLeeches(ctx context.Context, userID string) ([]entity.QuestionTask, error) {
+	tasks, err := s.repo.ListLeeches(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	fillRetrievability(tasks, s.clock.Now())
+	return tasks, nil
+}
+
+// UnsuspendLeech 解除暫停，保留 leech 標記與 lapse 次數，排程從目前狀態繼續
+func (s *reviewServiceImpl) UnsuspendLeech(ctx context.Context, userID, questionID string) (*entity.UserQuestionStats, error) {
+	unlock, err := s.repo.LockUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	stats, err := s.repo.GetUserStats(ctx, userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return nil, ErrStatsNotFound
+	}
+
+	if stats.Status != "SUSPENDED" {
+		return stats, nil
+	}
+
+	// 記成事件，重算與匯入回放時才不會又依 lapse 次數把它暫停回去
+	now := s.clock.Now()
+	unsuspend(stats, now)
+	event := entity.CardEvent{UserID: userID, QuestionID: questionID, Kind: entity.CardEventUnsuspend, At: now}
+	if err := s.repo.UpsertUserStatsWithEvent(ctx, *stats, event); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// unsuspend 解除暫停，暫停期間可能已經過期很久，馬上排進 now
+func unsuspend(stats *entity.UserQuestionStats, now time.Time) {
+	if stats.Status != "SUSPENDED" {
+		return
+	}
+	stats.Status = determineStatus(phaseOf(stats), stats.Streak)
+	if stats.NextReviewAt.After(now) {
+		stats.NextReviewAt = now
+	}
+}
+
+// ResetLeech 清除 leech 標記並把這題當成新題重新學習 (deep-dive 之後使用)
+// review_count 保留，維持 Fuzz 的可重現性
+func (s *reviewServiceImpl) ResetLeech(ctx context.Context, userID, questionID string) (*entity.UserQuestionStats, error) {
+	unlock, err := s.repo.LockUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	stats, err := s.repo.GetUserStats(ctx, userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return nil, ErrStatsNotFound
+	}
+
+	// 與新題相同，初始 EF 依題目難度與分類決定
+	question, err := s.repo.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := s.seedForQuestion(ctx, userID, question)
+	if err != nil {
+		return nil, err
+	}
+
+	// 記成事件，重算與匯入回放時在同一個時間點重設，之前的 lapse 不再算進來
+	now := s.clock.Now()
+	resetCard(stats, seed, now)
+	event := entity.CardEvent{UserID: userID, QuestionID: questionID, Kind: entity.CardEventReset, At: now}
+	if err := s.repo.UpsertUserStatsWithEvent(ctx, *stats, event); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// resetCard 把題目重設成新題 (review_count 與上次複習時間保留)，now 起就可以練習
+func resetCard(stats *entity.UserQuestionStats, seed questionSeed, now time.Time) {
+	stats.Lapses = 0
+	stats.IsLeech = false
+	stats.Status = "NEW"
+	stats.Streak = 0
+	stats.EaseFactor = seed.EaseFactor
+	stats.IntervalDays = 0
+	stats.IntervalMinutes = 0
+	stats.LearningStep = 0
+	stats.Stability = 0
+	stats.Difficulty = 0
+	stats.NextReviewAt = now
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+func TestApplyLapse(t *testing.T) {
+	suspend := entity.LeechPolicy{Threshold: 8, Action: entity.LeechActionSuspend}
+	tag := entity.LeechPolicy{Threshold: 8, Action: entity.LeechActionTag}
+
+	tests := []struct {
+		name        string
+		policy      entity.LeechPolicy
+		lapses      int // 這次 lapse 之前的次數
+		wantLeech   bool
+		wantSuspend bool
+	}{
+		{name: "below the threshold", policy: suspend, lapses: 6},
+		{name: "reaching the threshold suspends", policy: suspend, lapses: 7, wantLeech: true, wantSuspend: true},
+		{name: "reaching the threshold only tags", policy: tag, lapses: 7, wantLeech: true},
+		{name: "between triggers", policy: suspend, lapses: 8},
+		{name: "triggers again after threshold/2 more lapses", policy: suspend, lapses: 11, wantLeech: true, wantSuspend: true},
+		{name: "threshold 1 triggers on every lapse", policy: entity.LeechPolicy{Threshold: 1, Action: entity.LeechActionSuspend}, lapses: 4, wantLeech: true, wantSuspend: true},
+		{name: "disabled", policy: entity.LeechPolicy{}, lapses: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := entity.UserQuestionStats{Status: "REVIEW", Lapses: tt.lapses}
+			applyLapse(&stats, tt.policy)
+
+			if stats.Lapses != tt.lapses+1 {
+				t.Errorf("Lapses = %d, want %d", stats.Lapses, tt.lapses+1)
+			}
+			if stats.IsLeech != tt.wantLeech {
+				t.Errorf("IsLeech = %v, want %v", stats.IsLeech, tt.wantLeech)
+			}
+			if suspended := stats.Status == "SUSPENDED"; suspended != tt.wantSuspend {
+				t.Errorf("Status = %q, want suspended = %v", stats.Status, tt.wantSuspend)
+			}
+		})
+	}
+}
+
+func TestValidateLeechPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  entity.LeechPolicy
+		wantErr bool
+	}{
+		{name: "default", policy: DefaultLeechPolicy()},
+		{name: "tag", policy: entity.LeechPolicy{Threshold: 3, Action: entity.LeechActionTag}},
+		{name: "zero threshold", policy: entity.LeechPolicy{Threshold: 0, Action: entity.LeechActionSuspend}, wantErr: true},
+		{name: "unknown action", policy: entity.LeechPolicy{Threshold: 8, Action: "delete"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLeechPolicy(tt.policy)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("validateLeechPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidLeechPolicy) {
+				t.Errorf("error = %v, want ErrInvalidLeechPolicy", err)
+			}
+		})
+	}
+}
+
+func TestUnsuspend(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		stats      entity.UserQuestionStats
+		wantStatus string
+		wantNext   time.Time
+	}{
+		{
+			name:       "due in the future comes back now",
+			stats:      entity.UserQuestionStats{Status: "SUSPENDED", Streak: 2, IntervalDays: 10, NextReviewAt: now.AddDate(0, 0, 5)},
+			wantStatus: "REVIEW",
+			wantNext:   now,
+		},
+		{
+			name:       "overdue keeps its due date",
+			stats:      entity.UserQuestionStats{Status: "SUSPENDED", Streak: 0, IntervalDays: 1, NextReviewAt: now.AddDate(0, 0, -30)},
+			wantStatus: "LEARNING",
+			wantNext:   now.AddDate(0, 0, -30),
+		},
+		{
+			name:       "not suspended",
+			stats:      entity.UserQuestionStats{Status: "MASTERED", Streak: 7, IntervalDays: 60, NextReviewAt: now.AddDate(0, 0, 5)},
+			wantStatus: "MASTERED",
+			wantNext:   now.AddDate(0, 0, 5),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := tt.stats
+			unsuspend(&stats, now)
+			if stats.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", stats.Status, tt.wantStatus)
+			}
+			if !stats.NextReviewAt.Equal(tt.wantNext) {
+				t.Errorf("NextReviewAt = %v, want %v", stats.NextReviewAt, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestResetCard(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	last := now.AddDate(0, 0, -2)
+	stats := entity.UserQuestionStats{
+		Status:         "SUSPENDED",
+		IsLeech:        true,
+		Lapses:         8,
+		Streak:         1,
+		EaseFactor:     1.3,
+		IntervalDays:   1,
+		Stability:      0.4,
+		Difficulty:     9,
+		ReviewCount:    23,
+		LastReviewedAt: last,
+		NextReviewAt:   now.AddDate(0, 0, 1),
+	}
+
+	resetCard(&stats, questionSeed{EaseFactor: 2.3}, now)
+
+	want := entity.UserQuestionStats{
+		Status:         "NEW",
+		EaseFactor:     2.3,
+		ReviewCount:    23, // 保留，Fuzz 才能重現
+		LastReviewedAt: last,
+		NextReviewAt:   now,
+	}
+	if stats != want {
+		t.Errorf("resetCard() = %+v, want %+v", stats, want)
+	}
+}
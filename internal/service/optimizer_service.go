@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"letracker/internal/entity"
+	"letracker/internal/repository"
+	"letracker/pkg/optimizer"
+)
+
+// OptimizerService 依照使用者的 study_logs 擬合排程參數
+type OptimizerService interface {
+	// FitParams 重新擬合並儲存使用者的參數
+	FitParams(ctx context.Context, userID string) (*entity.SchedulerParams, error)
+
+	// GetParams 取得目前儲存的參數 (沒擬合過回傳 nil)
+	GetParams(ctx context.Context, userID string) (*entity.SchedulerParams, error)
+}
+
+type optimizerServiceImpl struct {
+	repo    repository.Repository
+	reviews ReviewService // 讀取合併提交的設定 (狀態對應表、時間窗、評分門檻)
+}
+
+// NewOptimizerService 建構子
+func NewOptimizerService(repo repository.Repository, reviews ReviewService) OptimizerService {
+	return &optimizerServiceImpl{repo: repo, reviews: reviews}
+}
+
+func (s *optimizerServiceImpl) FitParams(ctx context.Context, userID string) (*entity.SchedulerParams, error) {
+	// 1. 撈出全部 Logs，按題目分組
+	logs, err := s.repo.GetUserLogs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.reviews.GetStatusGradeMapping(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	windowMinutes, err := s.reviews.GetSessionWindow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	grading, err := s.reviews.GetGradingPolicy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byQuestion := make(map[string][]entity.SubmissionLog)
+	for _, log := range logs {
+		byQuestion[log.QuestionID] = append(byQuestion[log.QuestionID], log)
+	}
+
+	// 與回放相同：一次練習只算一次複習，評分由提交模式推導 (不會把練習中途的 TLE 當成一次記得)
+	window := time.Duration(windowMinutes) * time.Minute
+	histories := make([][]optimizer.Review, 0, len(byQuestion))
+	for _, questionLogs := range byQuestion {
+		sessions := collapseSessions(itemsFromLogs(questionLogs, statuses), window, grading.HardAfterWrongAttempts)
+		if len(sessions) == 0 {
+			continue
+		}
+		reviews := make([]optimizer.Review, len(sessions))
+		for i, session := range sessions {
+			reviews[i] = optimizer.Review{Date: session.at(), Grade: session.grade}
+		}
+		histories = append(histories, reviews)
+	}
+
+	// 2. 擬合
+	result, err := optimizer.Fit(histories, optimizer.DefaultOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 儲存
+	params := entity.SchedulerParams{
+		UserID:          userID,
+		HardModifier:    result.Params.HardModifier,
+		EasyBonus:       result.Params.EasyBonus,
+		RetentionBonus:  result.Params.RetentionBonus,
+		LogLoss:         result.LogLoss,
+		BaselineLogLoss: result.BaselineLogLoss,
+		ReviewCount:     result.ReviewCount,
+		FittedAt:        time.Now(),
+	}
+	if err := s.repo.SaveSchedulerParams(ctx, params); err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
+
+func (s *optimizerServiceImpl) GetParams(ctx context.Context, userID string) (*entity.SchedulerParams, error) {
+	return s.repo.GetSchedulerParams(ctx, userID)
+}
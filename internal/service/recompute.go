@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"letracker/internal/entity"
+)
+
+// ErrInvalidRecomputeScope 重算沒有指定範圍 (要給 UserID 或 All，擇一)
+var ErrInvalidRecomputeScope = errors.New("invalid recompute scope")
+
+// recomputeBatchSize 重算時每個 Transaction 寫入幾題
+const recomputeBatchSize = 200
+
+// RecomputeRequest 重算的範圍，UserID 與 All 擇一 (不小心漏給 user_id 不會變成重算所有人)
+type RecomputeRequest struct {
+	UserID string
+	All    bool // 所有有練習紀錄的使用者
+	DryRun bool // 只計算差異，不寫入
+}
+
+// RecomputeProgress 每處理完一位使用者回報一次
+type RecomputeProgress struct {
+	UserID    string
+	Done      int // 已處理幾位使用者
+	Total     int
+	Questions int // 這位使用者重算了幾題
+	Changed   int // 其中狀態有改變的題數
+}
+
+// RecomputeResult 重算結果
+type RecomputeResult struct {
+	Users     int
+	Questions int
+	Changed   int
+	// 狀態有改變的題目 (只有 DryRun 時回傳)
+	Diffs []entity.RecomputeDiff
+}
+
+func validateRecomputeRequest(req RecomputeRequest) error {
+	if req.UserID == "" && !req.All {
+		return fmt.Errorf("%w: user_id or all is required", ErrInvalidRecomputeScope)
+	}
+	if req.UserID != "" && req.All {
+		return fmt.Errorf("%w: user_id and all cannot be used together", ErrInvalidRecomputeScope)
+	}
+	return nil
+}
+
+// SubmitRecompute 建立背景重算工作並立即回傳，與匯入共用同一個佇列與 Worker (用 GetRecomputeJob 查進度)
+func (s *reviewServiceImpl) SubmitRecompute(ctx context.Context, req RecomputeRequest) (*entity.ImportJob, error) {
+	if err := validateRecomputeRequest(req); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	job := entity.ImportJob{
+		UserID:    req.UserID,
+		Kind:      entity.JobKindRecompute,
+		Status:    entity.ImportJobQueued,
+		Recompute: &entity.RecomputeProgress{DryRun: req.DryRun},
+		CreatedAt: s.clock.Now(),
+	}
+	job.ID, err = s.repo.CreateImportJob(ctx, job, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	s.wakeWorker()
+	return &job, nil
+}
+
+// GetRecomputeJob 取得重算工作的進度 (DryRun 的工作完成後附上差異)
+func (s *reviewServiceImpl) GetRecomputeJob(ctx context.Context, jobID string) (*entity.ImportJob, error) {
+	job, err := s.repo.GetRecomputeJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+// runRecomputeJob Worker 執行重算，每處理完一位使用者就更新一次進度
+func (s *reviewServiceImpl) runRecomputeJob(ctx context.Context, job *entity.ImportJob, payload []byte) {
+	var req RecomputeRequest
+	err := json.Unmarshal(payload, &req)
+
+	// 重新排隊的工作從頭開始
+	progress := &entity.RecomputeProgress{DryRun: req.DryRun}
+	job.Recompute = progress
+	if err == nil {
+		var result *RecomputeResult
+		result, err = s.Recompute(ctx, req, func(p RecomputeProgress) {
+			progress.TotalUsers = p.Total
+			progress.ProcessedUsers = p.Done
+			progress.Questions += p.Questions
+			progress.Changed += p.Changed
+			s.saveImportJob(ctx, *job)
+		})
+		if err == nil {
+			progress.Diffs = result.Diffs
+		}
+	}
+
+	s.finishJob(ctx, job, err)
+}
+
+// Recompute 以目前的演算法與設定，從 study_logs 重新回放並覆寫 user_question_stats 與 review_snapshots
+// 切換演算法或調整參數之後，舊的狀態不會自動更新，需要跑一次重算
+func (s *reviewServiceImpl) Recompute(ctx context.Context, req RecomputeRequest, report func(RecomputeProgress)) (*RecomputeResult, error) {
+	if err := validateRecomputeRequest(req); err != nil {
+		return nil, err
+	}
+
+	userIDs := []string{req.UserID}
+	if req.All {
+		ids, err := s.repo.ListUserIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = ids
+	}
+
+	result := &RecomputeResult{}
+	for i, userID := range userIDs {
+		diffs, questions, err := s.recomputeAndSave(ctx, userID, req.DryRun)
+		if err != nil {
+			return nil, fmt.Errorf("recompute %s: %w", userID, err)
+		}
+		if req.DryRun {
+			result.Diffs = append(result.Diffs, diffs...)
+		}
+
+		result.Users++
+		result.Questions += questions
+		result.Changed += len(diffs)
+		if report != nil {
+			report(RecomputeProgress{
+				UserID:    userID,
+				Done:      i + 1,
+				Total:     len(userIDs),
+				Questions: questions,
+				Changed:   len(diffs),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// recomputeAndSave 重算一位使用者並寫入 (DryRun 不寫入)
+// 重算期間鎖住這位使用者，避免即時評分或匯入在讀取與寫入之間被覆蓋
+// 狀態有改變的題目連同快照一起換掉，同一批在同一個 Transaction 裡
+func (s *reviewServiceImpl) recomputeAndSave(ctx context.Context, userID string, dryRun bool) ([]entity.RecomputeDiff, int, error) {
+	unlock, err := s.repo.LockUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer unlock()
+
+	diffs, snapshots, questions, err := s.recomputeUser(ctx, userID)
+	if err != nil || dryRun {
+		return diffs, questions, err
+	}
+
+	// 分批寫入，避免單一 Transaction 太大
+	for start := 0; start < len(diffs); start += recomputeBatchSize {
+		end := min(start+recomputeBatchSize, len(diffs))
+		var stats []entity.UserQuestionStats
+		var batchSnapshots []entity.ReviewSnapshot
+		for _, diff := range diffs[start:end] {
+			stats = append(stats, diff.After)
+			batchSnapshots = append(batchSnapshots, snapshots[diff.QuestionID]...)
+		}
+		if err := s.repo.BatchReplaceUserStats(ctx, stats, batchSnapshots); err != nil {
+			return nil, 0, err
+		}
+	}
+	return diffs, questions, nil
+}
+
+// recomputeUser 回放一位使用者所有題目的 Logs，回傳狀態有改變的題目、這些題目的快照與重算的題數 (不寫入 DB)
+// 回放規則與匯入相同：匯入的提交以天為單位，即時練習照當下的學習步驟 (學習中的題目不會被重設成以天為單位)
+func (s *reviewServiceImpl) recomputeUser(ctx context.Context, userID string) ([]entity.RecomputeDiff, map[string][]entity.ReviewSnapshot, int, error) {
+	session, err := s.newImportSession(ctx, userID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	logs, err := s.repo.GetUserLogs(ctx, userID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	cardEvents, err := s.repo.ListUserCardEvents(ctx, userID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	events := make(map[string][]entity.CardEvent)
+	for _, event := range cardEvents {
+		events[event.QuestionID] = append(events[event.QuestionID], event)
+	}
+
+	current, err := s.repo.ListUserStats(ctx, userID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	before := make(map[string]*entity.UserQuestionStats, len(current))
+	for i := range current {
+		before[current[i].QuestionID] = &current[i]
+	}
+
+	// 依題目分組 (Logs 已經按時間排序)
+	var questionIDs []string
+	byQuestion := make(map[string][]entity.SubmissionLog)
+	for _, log := range logs {
+		if _, ok := byQuestion[log.QuestionID]; !ok {
+			questionIDs = append(questionIDs, log.QuestionID)
+		}
+		byQuestion[log.QuestionID] = append(byQuestion[log.QuestionID], log)
+	}
+
+	var diffs []entity.RecomputeDiff
+	snapshots := make(map[string][]entity.ReviewSnapshot)
+	for _, questionID := range questionIDs {
+		question, err := s.repo.GetQuestionByID(ctx, questionID)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+
+		prev := before[questionID]
+		if prev != nil && prev.Status != "SUSPENDED" {
+			session.workload.remove(prev.NextReviewAt)
+		}
+
+		seed := seedFor(session.easePolicy, session.config, question)
+		initial := entity.UserQuestionStats{
+			UserID:     userID,
+			QuestionID: questionID,
+			EaseFactor: seed.EaseFactor,
+			Status:     "NEW",
+		}
+		opts := session.replayOptions(question, seed, events[questionID])
+		after, _, questionSnapshots := replaySessions(session.engines, opts, initial, collapseSessions(
+			itemsFromLogs(byQuestion[questionID], session.statuses), session.window, session.hardAfter,
+		))
+		if err := session.workload.err(); err != nil {
+			return nil, nil, 0, err
+		}
+		if after.Status != "SUSPENDED" {
+			session.workload.add(after.NextReviewAt)
+		}
+
+		if prev == nil || statsChanged(*prev, after) {
+			diffs = append(diffs, entity.RecomputeDiff{UserID: userID, QuestionID: questionID, Before: prev, After: after})
+			snapshots[questionID] = questionSnapshots
+		}
+	}
+
+	return diffs, snapshots, len(questionIDs), nil
+}
+
+// statsChanged 比較兩個狀態的排程是否不同 (時間只比到秒，DB 的精度不一定相同)
+func statsChanged(a, b entity.UserQuestionStats) bool {
+	return a.Status != b.Status ||
+		a.Streak != b.Streak ||
+		a.IntervalDays != b.IntervalDays ||
+		a.IntervalMinutes != b.IntervalMinutes ||
+		a.LearningStep != b.LearningStep ||
+		a.EaseFactor != b.EaseFactor ||
+		a.Stability != b.Stability ||
+		a.Difficulty != b.Difficulty ||
+		a.ReviewCount != b.ReviewCount ||
+		a.Lapses != b.Lapses ||
+		a.IsLeech != b.IsLeech ||
+		a.NextReviewAt.Unix() != b.NextReviewAt.Unix() ||
+		a.LastReviewedAt.Unix() != b.LastReviewedAt.Unix()
+}
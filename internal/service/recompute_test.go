@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateRecomputeRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     RecomputeRequest
+		wantErr bool
+	}{
+		{name: "one user", req: RecomputeRequest{UserID: "u"}},
+		{name: "all users", req: RecomputeRequest{All: true, DryRun: true}},
+		{name: "no scope", req: RecomputeRequest{DryRun: true}, wantErr: true},
+		{name: "both scopes", req: RecomputeRequest{UserID: "u", All: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRecomputeRequest(tt.req)
+			if tt.wantErr != errors.Is(err, ErrInvalidRecomputeScope) {
+				t.Errorf("validateRecomputeRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubmitRecomputeRejectsMissingScope(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := newTestService(repo, time.Now())
+
+	if _, err := svc.SubmitRecompute(context.Background(), RecomputeRequest{DryRun: true}); !errors.Is(err, ErrInvalidRecomputeScope) {
+		t.Errorf("SubmitRecompute() error = %v, want ErrInvalidRecomputeScope", err)
+	}
+	if len(repo.jobs) != 0 {
+		t.Error("an invalid request should not be queued")
+	}
+}
+
+func TestRecomputeDiff(t *testing.T) {
+	ctx := context.Background()
+	reviewedAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{userIDs: []string{"u"}}
+
+	// 兩題匯入的紀錄，其中一題之後在即時練習答錯，停在重新學習的第 2 步
+	history := ImportSubmissionRequest{History: []HistoryItem{
+		{Title: "Two Sum", Slug: "two-sum", Status: "Accepted", Timestamp: reviewedAt.AddDate(0, 0, -10).Unix(), SubmissionID: 1},
+		{Title: "Valid Anagram", Slug: "valid-anagram", Status: "Accepted", Timestamp: reviewedAt.AddDate(0, 0, -3).Unix(), SubmissionID: 2},
+	}}
+	if _, err := newTestService(repo, reviewedAt).ImportHistory(ctx, "u", history); err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+	again, good := 0, 2
+	if _, err := newTestService(repo, reviewedAt).ProcessReview(ctx, "u", ReviewRequest{QuestionID: "q-1", Grade: &again}); err != nil {
+		t.Fatalf("ProcessReview() error = %v", err)
+	}
+	if _, err := newTestService(repo, reviewedAt.Add(15*time.Minute)).ProcessReview(ctx, "u", ReviewRequest{QuestionID: "q-1", Grade: &good}); err != nil {
+		t.Fatalf("ProcessReview() error = %v", err)
+	}
+	learning := repo.stats["q-1"]
+	if learning.Status != "RELEARNING" {
+		t.Fatalf("live state = %s, want RELEARNING", learning.Status)
+	}
+
+	svc := newTestService(repo, reviewedAt.Add(time.Hour))
+
+	// 設定沒變：重算結果與目前狀態相同 (學習中的題目也不算改變)
+	result, err := svc.Recompute(ctx, RecomputeRequest{UserID: "u", DryRun: true}, nil)
+	if err != nil {
+		t.Fatalf("Recompute() error = %v", err)
+	}
+	if result.Users != 1 || result.Questions != 2 || result.Changed != 0 || len(result.Diffs) != 0 {
+		t.Errorf("unchanged recompute = %+v, want 2 questions without diffs", *result)
+	}
+
+	// 狀態被改過的題目才列出差異，DryRun 不寫入
+	tampered := repo.stats["q-2"]
+	original := tampered
+	tampered.IntervalDays, tampered.IntervalMinutes = 30, 30*24*60
+	repo.stats["q-2"] = tampered
+
+	result, err = svc.Recompute(ctx, RecomputeRequest{All: true, DryRun: true}, nil)
+	if err != nil {
+		t.Fatalf("Recompute() error = %v", err)
+	}
+	if result.Changed != 1 || len(result.Diffs) != 1 {
+		t.Fatalf("dry run = %+v, want one diff", *result)
+	}
+	diff := result.Diffs[0]
+	if diff.QuestionID != "q-2" || diff.Before == nil || *diff.Before != tampered || diff.After != original {
+		t.Errorf("diff = %+v, want q-2 from the tampered state back to %+v", diff, original)
+	}
+	if repo.stats["q-2"] != tampered || len(repo.replaced) != 0 {
+		t.Error("a dry run should not write")
+	}
+
+	// 實際重算只寫入有改變的題目，差異不回傳
+	var progress []RecomputeProgress
+	result, err = svc.Recompute(ctx, RecomputeRequest{UserID: "u"}, func(p RecomputeProgress) { progress = append(progress, p) })
+	if err != nil {
+		t.Fatalf("Recompute() error = %v", err)
+	}
+	if result.Changed != 1 || result.Diffs != nil {
+		t.Errorf("recompute = %+v, want one change without diffs", *result)
+	}
+	if len(repo.replaced) != 1 || repo.stats["q-2"] != original {
+		t.Errorf("replaced %+v, want only q-2 restored to %+v", repo.replaced, original)
+	}
+	if !reflect.DeepEqual(repo.stats["q-1"], learning) {
+		t.Errorf("learning card = %+v, want it untouched %+v", repo.stats["q-1"], learning)
+	}
+	if want := []RecomputeProgress{{UserID: "u", Done: 1, Total: 1, Questions: 2, Changed: 1}}; !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress = %+v, want %+v", progress, want)
+	}
+}
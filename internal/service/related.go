@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"letracker/internal/entity"
+	"letracker/pkg/srs"
+)
+
+var (
+	// ErrInvalidRelatedCreditPolicy 相關題目連動設定不合理
+	ErrInvalidRelatedCreditPolicy = errors.New("invalid related credit policy")
+	// ErrInvalidRelation 題目關聯不合理 (類型錯誤或關聯到自己)
+	ErrInvalidRelation = errors.New("invalid question relation")
+)
+
+// relationWeights 不同關聯的連動強度：延伸題幾乎是同一題，同模式的題目只沾一點光
+var relationWeights = map[string]float64{
+	entity.RelationFollowUp:    1.0,
+	entity.RelationVariant:     0.8,
+	entity.RelationSamePattern: 0.5,
+}
+
+// DefaultRelatedCreditPolicy 答對延後間隔的 20%，答錯把剩餘等待時間砍半
+func DefaultRelatedCreditPolicy() entity.RelatedCreditPolicy {
+	return entity.RelatedCreditPolicy{
+		SuccessCredit: 0.2,
+		FailurePull:   0.5,
+	}
+}
+
+// adjustRelated 依本題的評分調整相關題目的下次複習時間，規則見 relatedDue
+// 同一題可能有多種關聯，取最強的
+func adjustRelated(policy entity.RelatedCreditPolicy, related []entity.RelatedStats, grade int, now time.Time, maxInterval int, interview *entity.InterviewDeadline) []entity.RelatedAdjustment {
+	strongest := make(map[string]entity.RelatedStats)
+	for _, rs := range related {
+		if best, ok := strongest[rs.QuestionID]; !ok || relationWeights[rs.Relation] > relationWeights[best.Relation] {
+			strongest[rs.QuestionID] = rs
+		}
+	}
+
+	var adjustments []entity.RelatedAdjustment
+	for questionID, rs := range strongest {
+		weight := relationWeights[rs.Relation]
+		deadline := deadlineForQuestion(interview, &entity.Question{IsNeetcode150: rs.IsNeetcode150})
+		next, ok := relatedDue(policy, rs.UserQuestionStats, weight, grade, now, maxInterval, deadline)
+		if !ok {
+			continue
+		}
+		adjustments = append(adjustments, entity.RelatedAdjustment{
+			QuestionID:   questionID,
+			NextReviewAt: next,
+			Grade:        grade,
+			Weight:       weight,
+		})
+	}
+	return adjustments
+}
+
+// relatedDue 相關題目連動後的下次複習時間，不需要調整時回傳 false：
+// - Good / Easy：往後延 round(間隔 * SuccessCredit * 關聯強度) 天，最多到「上次複習 + 間隔 + 延後天數」
+// (連續答對相關題目不會一直往後疊)，且受間隔上限與面試最後一週限制
+// - Again：剩餘等待時間縮短 FailurePull * 關聯強度 的比例 (已到期的不動)
+// - Hard：不調整
+// 只調整已畢業 (複習階段) 的題目，學習步驟中與暫停的題目不受影響
+func relatedDue(policy entity.RelatedCreditPolicy, st entity.UserQuestionStats, weight float64, grade int, now time.Time, maxInterval int, deadline time.Time) (time.Time, bool) {
+	if st.Status == "SUSPENDED" || phaseOf(&st) != srs.PhaseReview || st.IntervalDays <= 0 {
+		return time.Time{}, false
+	}
+
+	switch {
+	case grade >= 2:
+		days := int(math.Round(float64(st.IntervalDays) * policy.SuccessCredit * weight))
+		if days == 0 {
+			return time.Time{}, false
+		}
+		limit := st.IntervalDays + days
+		if maxInterval > 0 {
+			limit = min(limit, maxInterval)
+		}
+		limit = srs.CapToDeadline(st.LastReviewedAt, deadline, limit)
+
+		next := st.NextReviewAt.AddDate(0, 0, days)
+		if latest := st.LastReviewedAt.AddDate(0, 0, limit); next.After(latest) {
+			next = latest
+		}
+		if !next.After(st.NextReviewAt) {
+			return time.Time{}, false
+		}
+		return next, true
+	case grade == 0:
+		remaining := st.NextReviewAt.Sub(now)
+		if remaining <= 0 || policy.FailurePull == 0 {
+			return time.Time{}, false
+		}
+		return now.Add(time.Duration(float64(remaining) * (1 - policy.FailurePull*weight))), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// relatedAdjustments 讀取設定與相關題目，計算這次評分要連動的調整
+func (s *reviewServiceImpl) relatedAdjustments(ctx context.Context, userID, questionID string, grade int, now time.Time, interview *entity.InterviewDeadline) ([]entity.RelatedAdjustment, error) {
+	policy, err := s.GetRelatedCreditPolicy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.SuccessCredit == 0 && policy.FailurePull == 0 {
+		return nil, nil
+	}
+
+	config, err := s.GetSchedulerConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	related, err := s.repo.ListRelatedStats(ctx, userID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	return adjustRelated(policy, related, grade, now, config.MaximumInterval, interview), nil
+}
+
+func (s *reviewServiceImpl) GetRelatedCreditPolicy(ctx context.Context, userID string) (entity.RelatedCreditPolicy, error) {
+	policy, err := s.repo.GetRelatedCreditPolicy(ctx, userID)
+	if err != nil {
+		return entity.RelatedCreditPolicy{}, err
+	}
+	if policy == nil {
+		return DefaultRelatedCreditPolicy(), nil
+	}
+	return *policy, nil
+}
+
+func (s *reviewServiceImpl) SetRelatedCreditPolicy(ctx context.Context, userID string, policy entity.RelatedCreditPolicy) error {
+	if policy.SuccessCredit < 0 || policy.SuccessCredit > 1 {
+		return fmt.Errorf("%w: success_credit must be between 0 and 1", ErrInvalidRelatedCreditPolicy)
+	}
+	if policy.FailurePull < 0 || policy.FailurePull > 1 {
+		return fmt.Errorf("%w: failure_pull must be between 0 and 1", ErrInvalidRelatedCreditPolicy)
+	}
+	return s.repo.SaveRelatedCreditPolicy(ctx, userID, policy)
+}
+
+func (s *reviewServiceImpl) ListRelations(ctx context.Context, questionID string) ([]entity.QuestionRelation, error) {
+	return s.repo.ListRelations(ctx, questionID)
+}
+
+func (s *reviewServiceImpl) AddRelation(ctx context.Context, rel entity.QuestionRelation) error {
+	if rel.QuestionID == rel.RelatedID {
+		return fmt.Errorf("%w: a question cannot be related to itself", ErrInvalidRelation)
+	}
+	if _, ok := relationWeights[rel.Relation]; !ok {
+		return fmt.Errorf("%w: relation must be %q, %q or %q", ErrInvalidRelation,
+			entity.RelationSamePattern, entity.RelationFollowUp, entity.RelationVariant)
+	}
+	return s.repo.SaveRelation(ctx, rel)
+}
+
+func (s *reviewServiceImpl) RemoveRelation(ctx context.Context, questionID, relatedID string) error {
+	return s.repo.DeleteRelation(ctx, questionID, relatedID)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+func TestRelatedDue(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	policy := DefaultRelatedCreditPolicy()
+
+	// 1/1 複習過，間隔 10 天，1/11 到期
+	card := entity.UserQuestionStats{
+		Status:         "REVIEW",
+		IntervalDays:   10,
+		LastReviewedAt: day(1),
+		NextReviewAt:   day(11),
+	}
+	with := func(change func(*entity.UserQuestionStats)) entity.UserQuestionStats {
+		st := card
+		change(&st)
+		return st
+	}
+
+	tests := []struct {
+		name        string
+		st          entity.UserQuestionStats
+		weight      float64
+		grade       int
+		now         time.Time
+		maxInterval int
+		deadline    time.Time
+		want        time.Time
+		wantOK      bool
+	}{
+		{name: "Good pushes by 20% of the interval", st: card, weight: 1, grade: 2, now: day(5), want: day(13), wantOK: true},
+		{name: "same pattern pushes less", st: card, weight: 0.5, grade: 3, now: day(5), want: day(12), wantOK: true},
+		{name: "already pushed once", st: with(func(st *entity.UserQuestionStats) { st.NextReviewAt = day(13) }), weight: 1, grade: 2, now: day(5)},
+		{name: "capped by the maximum interval", st: card, weight: 1, grade: 2, now: day(5), maxInterval: 11, want: day(12), wantOK: true},
+		{name: "not pushed past the final week", st: card, weight: 1, grade: 2, now: day(5), deadline: day(12)},
+		{name: "Again pulls the remaining wait in", st: card, weight: 1, grade: 0, now: day(5), want: day(8), wantOK: true},
+		{name: "Again on an overdue card", st: card, weight: 1, grade: 0, now: day(12)},
+		{name: "Hard leaves the card alone", st: card, weight: 1, grade: 1, now: day(5)},
+		{name: "suspended", st: with(func(st *entity.UserQuestionStats) { st.Status = "SUSPENDED" }), weight: 1, grade: 2, now: day(5)},
+		{name: "still learning", st: with(func(st *entity.UserQuestionStats) { st.Status = "LEARNING"; st.IntervalDays = 0 }), weight: 1, grade: 0, now: day(5)},
+		{name: "relearning", st: with(func(st *entity.UserQuestionStats) { st.Status = "RELEARNING" }), weight: 1, grade: 2, now: day(5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := relatedDue(policy, tt.st, tt.weight, tt.grade, tt.now, tt.maxInterval, tt.deadline)
+			if ok != tt.wantOK {
+				t.Fatalf("relatedDue() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("relatedDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdjustRelated(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	stats := func(questionID string) entity.UserQuestionStats {
+		return entity.UserQuestionStats{
+			QuestionID:     questionID,
+			Status:         "REVIEW",
+			IntervalDays:   10,
+			LastReviewedAt: day(1),
+			NextReviewAt:   day(11),
+		}
+	}
+	related := []entity.RelatedStats{
+		{UserQuestionStats: stats("a"), Relation: entity.RelationSamePattern},
+		{UserQuestionStats: stats("a"), Relation: entity.RelationFollowUp},
+		{UserQuestionStats: stats("b"), Relation: entity.RelationVariant, IsNeetcode150: true},
+		{UserQuestionStats: stats("c"), Relation: entity.RelationSamePattern},
+	}
+
+	tests := []struct {
+		name      string
+		interview *entity.InterviewDeadline
+		want      map[string]entity.RelatedAdjustment
+	}{
+		{
+			name: "strongest relation wins",
+			want: map[string]entity.RelatedAdjustment{
+				"a": {QuestionID: "a", NextReviewAt: day(13), Grade: 2, Weight: 1},
+				"b": {QuestionID: "b", NextReviewAt: day(13), Grade: 2, Weight: 0.8},
+				"c": {QuestionID: "c", NextReviewAt: day(12), Grade: 2, Weight: 0.5},
+			},
+		},
+		{
+			name:      "interview limited to NeetCode 150 only holds those back",
+			interview: &entity.InterviewDeadline{Date: day(12), Scope: entity.DeadlineScopeNeetCode150},
+			want: map[string]entity.RelatedAdjustment{
+				"a": {QuestionID: "a", NextReviewAt: day(13), Grade: 2, Weight: 1},
+				"c": {QuestionID: "c", NextReviewAt: day(12), Grade: 2, Weight: 0.5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adjustments := adjustRelated(DefaultRelatedCreditPolicy(), related, 2, day(5), 0, tt.interview)
+			if len(adjustments) != len(tt.want) {
+				t.Fatalf("got %d adjustments, want %d: %+v", len(adjustments), len(tt.want), adjustments)
+			}
+			for _, got := range adjustments {
+				want, ok := tt.want[got.QuestionID]
+				if !ok {
+					t.Errorf("unexpected adjustment for %s", got.QuestionID)
+					continue
+				}
+				if !got.NextReviewAt.Equal(want.NextReviewAt) || got.Grade != want.Grade || got.Weight != want.Weight {
+					t.Errorf("adjustment for %s = %+v, want %+v", got.QuestionID, got, want)
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"sort"
 	"time"
 
@@ -13,21 +15,96 @@ import (
 // ReviewService 定義所有與複習相關的業務邏輯
 type ReviewService interface {
 	// ProcessReview 處理使用者當下的練習提交 (單題)
-	ProcessReview(ctx context.Context, userID string, req ReviewRequest) (*srs.ReviewOutput, error)
+	// 沒有給 Grade 時，依 Signals 與使用者的評分門檻自動推導
+	ProcessReview(ctx context.Context, userID string, req ReviewRequest) (*ReviewResult, error)
 
-	// ImportHistory 處理從 Extension 抓來的整包歷史紀錄 (批次)
-	ImportHistory(ctx context.Context, userID string, req ImportSubmissionRequest) error
+	// ImportHistory 處理從 Extension 抓來的整包歷史紀錄 (批次)，也可以只送上次同步之後的新紀錄
+	// 已經匯入過的 submission 會被跳過，重複呼叫是安全的
+	// 新紀錄會與該題既有的 Logs (含即時練習) 合併後從頭回放，不會覆蓋即時練習的結果
+	ImportHistory(ctx context.Context, userID string, req ImportSubmissionRequest) (*ImportResult, error)
+	// SubmitImport 把匯入交給背景 Worker，立即回傳工作 (用 GetImportJob 查進度)
+	SubmitImport(ctx context.Context, userID string, req ImportSubmissionRequest) (*entity.ImportJob, error)
+	// PreviewImport 跑一次匯入的分組與回放但不寫入，回傳每題目前與匯入後的狀態差異
+	PreviewImport(ctx context.Context, userID string, req ImportSubmissionRequest) (*ImportPreview, error)
+	GetImportJob(ctx context.Context, userID, jobID string) (*entity.ImportJob, error)
+	// StartImportWorkers 啟動處理匯入工作的 Worker Pool (Server 啟動時呼叫一次)
+	StartImportWorkers(ctx context.Context, workers int)
+	// GetSyncCursor 取得上次匯入到哪一筆 submission (Extension 只需要抓之後的紀錄)
+	GetSyncCursor(ctx context.Context, userID string) (*entity.SyncCursor, error)
+	// Recompute 以目前的演算法從 study_logs 重算狀態 (切換演算法或調整參數之後使用)，同步執行 (CLI 用)
+	// 每處理完一位使用者呼叫一次 report (可為 nil)
+	Recompute(ctx context.Context, req RecomputeRequest, report func(RecomputeProgress)) (*RecomputeResult, error)
+	// SubmitRecompute 把重算交給背景 Worker，立即回傳工作 (用 GetRecomputeJob 查進度)
+	SubmitRecompute(ctx context.Context, req RecomputeRequest) (*entity.ImportJob, error)
+	GetRecomputeJob(ctx context.Context, jobID string) (*entity.ImportJob, error)
 
-	GetTodayTasks(ctx context.Context, userID string) ([]entity.QuestionTask, error)
+	GetTodayTasks(ctx context.Context, userID string, query TaskQuery) ([]entity.QuestionTask, error)
+
+	// GetQuestionStats 取得單題的 SRS 狀態與目前的記憶保留率 (沒練習過回傳 nil)
+	GetQuestionStats(ctx context.Context, userID, questionID string) (*entity.QuestionStats, error)
+
+	// GetQuestionLogs 取得單題的練習紀錄 (回放的紀錄含排程的計算過程，用於稽核)
+	GetQuestionLogs(ctx context.Context, userID, questionID string) ([]entity.SubmissionLog, error)
+	// GetQuestionTimeline 取得單題每次練習後的排程狀態 (給圖表用)
+	GetQuestionTimeline(ctx context.Context, userID, questionID string) ([]entity.ReviewSnapshot, error)
+
+	// Forecast 模擬未來 N 天的複習量與花費時間
+	Forecast(ctx context.Context, userID string, req ForecastRequest) (*srs.Forecast, error)
+
+	// GetAlgorithm / SetAlgorithm 讀取與切換使用者的排程演算法
+	GetAlgorithm(ctx context.Context, userID string) (string, error)
+	SetAlgorithm(ctx context.Context, userID, algorithm string) error
+
+	// GetSchedulerConfig / SetSchedulerConfig 讀取與設定排程參數 (間隔上限、EF 底限、倍率、Fuzz)
+	GetSchedulerConfig(ctx context.Context, userID string) (entity.SchedulerConfig, error)
+	SetSchedulerConfig(ctx context.Context, userID string, config entity.SchedulerConfig) (entity.SchedulerConfig, error)
+	// PreviewSchedulerConfig 儲存前先看範例題目在新設定下會怎麼排
+	PreviewSchedulerConfig(ctx context.Context, userID string, config entity.SchedulerConfig, questionID string) (*SchedulerPreview, error)
+
+	// GetInitialEasePolicy / SetInitialEasePolicy 新題依難度與分類的初始 EF (預設值 + 使用者覆寫)
+	GetInitialEasePolicy(ctx context.Context, userID string) (entity.InitialEasePolicy, error)
+	SetInitialEasePolicy(ctx context.Context, userID string, overrides entity.InitialEasePolicy) (entity.InitialEasePolicy, error)
+
+	// GetGradingPolicy / SetGradingPolicy 讀取與設定自動評分的門檻
+	GetGradingPolicy(ctx context.Context, userID string) (entity.GradingPolicy, error)
+	SetGradingPolicy(ctx context.Context, userID string, policy entity.GradingPolicy) (entity.GradingPolicy, error)
+	// 匯入時 LeetCode 狀態對應的評分 (例如 Compile Error 略過、TLE 算 Hard)
+	GetStatusGradeMapping(ctx context.Context, userID string) (entity.StatusGradeMapping, error)
+	SetStatusGradeMapping(ctx context.Context, userID string, overrides entity.StatusGradeMapping) (entity.StatusGradeMapping, error)
+	// 匯入時把同一題時間窗內的連續提交合併成一次練習 (分鐘，0 代表不合併)
+	GetSessionWindow(ctx context.Context, userID string) (int, error)
+	SetSessionWindow(ctx context.Context, userID string, minutes int) error
+
+	// Leech (一直答錯的題目) 相關
+	GetLeechPolicy(ctx context.Context, userID string) (entity.LeechPolicy, error)
+	SetLeechPolicy(ctx context.Context, userID string, policy entity.LeechPolicy) error
+	ListLeeches(ctx context.Context, userID string) ([]entity.QuestionTask, error)
+	UnsuspendLeech(ctx context.Context, userID, questionID string) (*entity.UserQuestionStats, error)
+	ResetLeech(ctx context.Context, userID, questionID string) (*entity.UserQuestionStats, error)
+
+	// 題目關聯圖：相關題目的複習會互相連動
+	ListRelations(ctx context.Context, questionID string) ([]entity.QuestionRelation, error)
+	AddRelation(ctx context.Context, rel entity.QuestionRelation) error
+	RemoveRelation(ctx context.Context, questionID, relatedID string) error
+	GetRelatedCreditPolicy(ctx context.Context, userID string) (entity.RelatedCreditPolicy, error)
+	SetRelatedCreditPolicy(ctx context.Context, userID string, policy entity.RelatedCreditPolicy) error
+
+	// 面試模式：面試前最後一週保證每題都會再複習一次
+	GetInterviewDeadline(ctx context.Context, userID string) (*entity.InterviewDeadline, error)
+	SetInterviewDeadline(ctx context.Context, userID string, deadline entity.InterviewDeadline) (*entity.InterviewDeadline, error)
+	ClearInterviewDeadline(ctx context.Context, userID string) error
 }
 
 type reviewServiceImpl struct {
-	repo repository.Repository
+	repo  repository.Repository
+	clock srs.Clock
+	// 有新的匯入工作時叫醒閒置的 Worker
+	importWake chan struct{}
 }
 
 // NewReviewService 建構子
 func NewReviewService(repo repository.Repository) ReviewService {
-	return &reviewServiceImpl{repo: repo}
+	return &reviewServiceImpl{repo: repo, clock: srs.SystemClock{}, importWake: make(chan struct{}, 1)}
 }
 
 // =========================================================
@@ -36,7 +113,46 @@ func NewReviewService(repo repository.Repository) ReviewService {
 
 type ReviewRequest struct {
 	QuestionID string
-	Grade      int // 0-3
+	Grade      *int // 0-3，nil 代表由 Signals 推導
+
+	// Signals 原始訊號 (花費時間、錯誤次數、提示)，Grade 為 nil 時必填
+	Signals *GradingSignals
+
+	// Explain 為 true 時，結果會附上排程的計算過程
+	Explain bool
+}
+
+// ReviewResult ProcessReview 的結果
+type ReviewResult struct {
+	srs.ReviewOutput
+	Grade int // 實際使用的評分 (可能是由訊號推導出來的)
+
+	// Explanation 排程的計算過程 (只有 ReviewRequest.Explain 時才有)
+	Explanation []entity.TraceStep
+
+	// RelatedAdjusted 連動調整了幾題相關題目的下次複習時間
+	RelatedAdjusted int
+}
+
+// ErrGradeRequired 既沒有 Grade 也沒有 Signals
+var ErrGradeRequired = errors.New("either grade or grading signals are required")
+
+// ErrQuestionNotFound 題目不存在
+var ErrQuestionNotFound = repository.ErrQuestionNotFound
+
+// 每日任務的排序方式
+const (
+	TaskSortOverdue        = "overdue"        // 依相對逾期程度 (預設)
+	TaskSortRetrievability = "retrievability" // 依記憶保留率，越可能忘記越前面
+)
+
+// TaskQuery 每日任務的查詢條件
+type TaskQuery struct {
+	Limit  int    // 最多回傳幾題 (預設 3)
+	SortBy string // TaskSortOverdue / TaskSortRetrievability
+
+	// MaxRetrievability > 0 時，只回傳記憶保留率低於此值的題目 (例如 0.7)，不限是否到期
+	MaxRetrievability float64
 }
 
 type ImportSubmissionRequest struct {
@@ -48,52 +164,158 @@ type HistoryItem struct {
 	Slug      string `json:"slug"`
 	Status    string `json:"status"`    // "Accepted", "Wrong Answer" ...
 	Timestamp int64  `json:"timestamp"` // Unix timestamp
+	// LeetCode 的 submission id，用來避免重複匯入 (0 代表不知道，每次都會寫入)
+	SubmissionID int64 `json:"submission_id"`
+
+	// 題目資訊 (選填)，用來決定新題的初始 EF
+	Difficulty string `json:"difficulty"` // "Easy" / "Medium" / "Hard"
+	Category   string `json:"category"`   // 例如 "Graphs"
 }
 
 // =========================================================
 // 1. ProcessReview (單題即時處理)
 // =========================================================
 
-func (s *reviewServiceImpl) ProcessReview(ctx context.Context, userID string, req ReviewRequest) (*srs.ReviewOutput, error) {
+func (s *reviewServiceImpl) ProcessReview(ctx context.Context, userID string, req ReviewRequest) (*ReviewResult, error) {
+	// 0. 決定評分：使用者直接給的優先，否則由訊號推導
+	var grade int
+	switch {
+	case req.Grade != nil:
+		grade = *req.Grade
+	case req.Signals != nil:
+		derived, err := s.gradeFromSignals(ctx, userID, req.QuestionID, *req.Signals)
+		if err != nil {
+			return nil, err
+		}
+		grade = derived
+	default:
+		return nil, ErrGradeRequired
+	}
+
+	// 同一位使用者的評分與匯入依序執行，避免讀到舊狀態之後互相覆蓋
+	unlock, err := s.repo.LockUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// 1. 取得目前狀態 (如果沒有則初始化)
 	currentStats, err := s.repo.GetUserStats(ctx, userID, req.QuestionID)
 	if err != nil {
 		return nil, err
 	}
 
+	// 依題目難度與分類決定新題的初始 EF 與第二次複習間隔
+	// 只有新題 (EF) 與即將第二次複習的題目 (間隔) 用得到，其他時候不必查題目與設定
+	var seed questionSeed
+	if currentStats == nil || currentStats.Streak == 1 {
+		question, err := s.repo.GetQuestionByID(ctx, req.QuestionID)
+		if err != nil {
+			return nil, err
+		}
+		seed, err = s.seedForQuestion(ctx, userID, question)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// 處理第一次練習的情況
 	if currentStats == nil {
 		currentStats = &entity.UserQuestionStats{
 			IntervalDays: 0,
-			EaseFactor:   2.5,
+			EaseFactor:   seed.EaseFactor,
 			Streak:       0,
 		}
 	}
 
-	// 2. 執行 SRS 演算法
+	// 2. 執行 SRS 演算法 (依使用者選擇的 Scheduler)
+	// 即時練習啟用學習步驟：新題與答錯的題目會在同一天內再出現
+	workload := newWorkloadOracle(ctx, s.repo, userID)
+	if currentStats.Status != "SUSPENDED" {
+		workload.remove(currentStats.NextReviewAt)
+	}
+	engine, err := s.engineFor(ctx, userID,
+		srs.WithWorkload(workload),
+		srs.WithLearningSteps(srs.DefaultLearningSteps()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	now := engine.Now()
+
+	leechPolicy, err := s.GetLeechPolicy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	interview, err := s.GetInterviewDeadline(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	deadline, err := s.deadlineFor(ctx, interview, req.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
 	algoInput := srs.ReviewInput{
 		CurrentInterval: currentStats.IntervalDays,
 		CurrentEF:       currentStats.EaseFactor,
 		Repetitions:     currentStats.Streak,
-		Grade:           req.Grade,
-		ActualDays:      0, // 即時練習通常不需要算 Retention Bonus
+		Grade:           grade,
+		ActualDays:      elapsedDays(currentStats.LastReviewedAt, now), // 提早打折、晚做加成，與回放一致
+		Stability:       currentStats.Stability,
+		Difficulty:      currentStats.Difficulty,
+		ReviewedAt:      now,
+		Phase:           phaseOf(currentStats),
+		Step:            currentStats.LearningStep,
+		Deadline:        deadline,
+		Explain:         req.Explain,
+		SecondIntervals: seed.SecondIntervals,
 	}
 
-	result := srs.CalculateNextReview(algoInput)
+	reviewCount := currentStats.ReviewCount + 1
+	result := engine.Review(srs.FuzzKey{
+		UserID:      userID,
+		QuestionID:  req.QuestionID,
+		ReviewCount: reviewCount,
+	}, algoInput)
+	if err := workload.err(); err != nil {
+		return nil, err
+	}
 
 	// 3. 更新 DB (Stats)
 	newStats := entity.UserQuestionStats{
-		UserID:         userID,
-		QuestionID:     req.QuestionID,
-		Streak:         result.Repetitions,
-		EaseFactor:     result.EaseFactor,
-		IntervalDays:   result.Interval,
-		NextReviewAt:   result.NextReviewAt,
-		LastReviewedAt: time.Now(),
-		Status:         determineStatus(result.Repetitions),
+		UserID:          userID,
+		QuestionID:      req.QuestionID,
+		Streak:          result.Repetitions,
+		EaseFactor:      result.EaseFactor,
+		IntervalDays:    result.Interval,
+		IntervalMinutes: result.IntervalMinutes,
+		LearningStep:    result.Step,
+		NextReviewAt:    result.NextReviewAt,
+		LastReviewedAt:  now,
+		Status:          determineStatus(result.Phase, result.Repetitions),
+		Stability:       result.Stability,
+		Difficulty:      result.Difficulty,
+		ReviewCount:     reviewCount,
+		Lapses:          currentStats.Lapses,
+		IsLeech:         currentStats.IsLeech,
+	}
+
+	// 已暫停的題目仍可手動練習，但維持暫停
+	if currentStats.Status == "SUSPENDED" {
+		newStats.Status = "SUSPENDED"
+	}
+	if result.Lapsed {
+		applyLapse(&newStats, leechPolicy)
 	}
 
-	if err := s.repo.UpsertUserStats(ctx, newStats); err != nil {
+	// 相關題目連動：答對延後、答錯提前，與本題狀態在同一個 Transaction 裡更新
+	related, err := s.relatedAdjustments(ctx, userID, req.QuestionID, grade, now, interview)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpsertUserStatsWithRelated(ctx, newStats, related); err != nil {
 		return nil, err
 	}
 
@@ -102,178 +324,883 @@ func (s *reviewServiceImpl) ProcessReview(ctx context.Context, userID string, re
 		UserID:       userID,
 		QuestionID:   req.QuestionID,
 		Status:       "SOLVED", // 這裡簡化，假設 ProcessReview 是做對了才呼叫，或需擴充 Request
-		MasteryLevel: req.Grade,
-		Date:         time.Now(),
+		MasteryLevel: grade,
+		Date:         now,
+	}
+	if req.Signals != nil {
+		log.TimeTakenSeconds = req.Signals.TimeTakenSeconds
 	}
 	// 如果 Grade 是 0，視為 Failed
-	if req.Grade == 0 {
+	if grade == 0 {
 		log.Status = "FAILED"
 	}
 
 	if err := s.repo.CreateLog(ctx, log); err != nil {
 		return nil, err
 	}
+	if err := s.repo.SaveSnapshots(ctx, []entity.ReviewSnapshot{snapshotOf(newStats, grade)}); err != nil {
+		return nil, err
+	}
 
-	return &result, nil
+	return &ReviewResult{
+		ReviewOutput:    result,
+		Grade:           grade,
+		Explanation:     traceOf(result.Trace),
+		RelatedAdjusted: len(related),
+	}, nil
 }
 
 // =========================================================
 // 2. ImportHistory (歷史回放與匯入)
 // =========================================================
-//
-// // 定義一個內部使用的 struct，專門給 Replay 邏輯用
+
+// replayOptions 回放時套用的使用者設定
+type replayOptions struct {
+	leech       entity.LeechPolicy
+	deadline    time.Time // 面試日期 (不在範圍內為零值)
+	seed        questionSeed
+	related     entity.RelatedCreditPolicy
+	maxInterval int
+	events      []entity.CardEvent // 這題練習以外的事件 (按時間排序)，回放時依序套用
+}
+
+// ImportResult 匯入結果
+type ImportResult struct {
+	Imported int                       // 新寫入的紀錄
+	Skipped  int                       // 之前已經匯入過的紀錄
+	Ignored  int                       // 依狀態對應表略過的紀錄
+	Slugs    []entity.ImportSlugResult // 每題的結果 (含失敗原因)
+}
+
 type replayItem struct {
-	Timestamp time.Time
-	Status    string
-	Title     string
+	SubmissionID int64
+	Timestamp    time.Time
+	Grade        int // 依使用者的狀態對應表決定
+	Status       string
+	Title        string
+	Difficulty   string
+	Category     string
+	Logged       bool // 已經寫入 study_logs (合併回放時的既有紀錄)，不再寫一次 Log
+	Manual       bool // 即時練習 (不是匯入的 submission)，評分是使用者給的
+
+	TimeTakenSeconds int // 只有由 Log 還原時才有 (預測統計花費時間用)
+}
+
+// replayEngines 回放使用的 Engine (共用同一個負載快取)
+// 匯入的提交以「天」為單位、不套用學習步驟；即時練習與當下評分相同，套用學習步驟
+type replayEngines struct {
+	imported *srs.Engine
+	live     *srs.Engine
+}
+
+// importSession 同一次匯入共用的設定與快取
+type importSession struct {
+	userID     string
+	engines    replayEngines
+	workload   *workloadOracle
+	leech      entity.LeechPolicy
+	interview  *entity.InterviewDeadline
+	easePolicy entity.InitialEasePolicy
+	config     entity.SchedulerConfig
+	related    entity.RelatedCreditPolicy
+	seen       map[int64]bool // 之前匯入過的 submission
+	statuses   entity.StatusGradeMapping
+	window     time.Duration // 合併提交的時間窗
+	hardAfter  int
+}
+
+// replayOptions 單題回放時套用的設定
+func (session *importSession) replayOptions(question *entity.Question, seed questionSeed, events []entity.CardEvent) replayOptions {
+	return replayOptions{
+		leech:       session.leech,
+		deadline:    deadlineForQuestion(session.interview, question),
+		seed:        seed,
+		related:     session.related,
+		maxInterval: session.config.MaximumInterval,
+		events:      events,
+	}
+}
+
+func (s *reviewServiceImpl) ImportHistory(ctx context.Context, userID string, req ImportSubmissionRequest) (*ImportResult, error) {
+	return s.importHistory(ctx, userID, req, nil)
+}
+
+// importHistory 匯入的主流程，每處理完一題就呼叫 report (可為 nil)
+// 單題失敗不會中斷整批匯入，失敗原因記在 ImportResult.Slugs
+func (s *reviewServiceImpl) importHistory(ctx context.Context, userID string, req ImportSubmissionRequest, report func(entity.ImportSlugResult)) (*ImportResult, error) {
+	session, err := s.newImportSession(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 資料前處理：按時間排序並依題目分組
+	batch := groupHistory(req.History, session.seen, session.statuses)
+	result := &ImportResult{Skipped: batch.skipped, Ignored: batch.ignored}
+
+	// 2. 逐題處理
+	failed := false
+	for _, slug := range batch.slugs {
+		slugResult := entity.ImportSlugResult{Slug: slug}
+
+		// 全部都匯入過：狀態不會改變，不需要重新回放
+		if batch.fresh[slug] > 0 {
+			replay, err := s.importSlug(ctx, session, slug, batch.items[slug])
+			if replay != nil {
+				slugResult.Imported = len(replay.logs)
+				result.Skipped += replay.duplicates
+			}
+			if err != nil {
+				slugResult.Error = err.Error()
+				failed = true
+			}
+		}
+
+		result.Imported += slugResult.Imported
+		result.Slugs = append(result.Slugs, slugResult)
+		if report != nil {
+			report(slugResult)
+		}
+	}
+
+	// 3. 更新同步游標：記下這包裡最新的 submission
+	// 有題目失敗時不推進，下次同步會再送一次 (成功的部分會被跳過)
+	if len(req.History) > 0 && !failed {
+		cursor := entity.SyncCursor{UserID: userID}
+		var lastTimestamp int64
+		for _, item := range req.History {
+			cursor.LastSubmissionID = max(cursor.LastSubmissionID, item.SubmissionID)
+			lastTimestamp = max(lastTimestamp, item.Timestamp)
+		}
+		cursor.LastTimestamp = time.Unix(lastTimestamp, 0)
+		if err := s.repo.SaveSyncCursor(ctx, cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// newImportSession 載入匯入需要的使用者設定
+func (s *reviewServiceImpl) newImportSession(ctx context.Context, userID string) (*importSession, error) {
+	config, err := s.GetSchedulerConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一批匯入的題目共用負載快取，讓它們在 Fuzz 範圍內互相錯開
+	// 歷史紀錄以「天」為單位，不套用學習步驟；合併回放的即時練習照當下的規則走學習步驟
+	workload := newWorkloadOracle(ctx, s.repo, userID)
+	imported, err := s.engineWith(ctx, userID, toSrsConfig(config), srs.WithWorkload(workload))
+	if err != nil {
+		return nil, err
+	}
+	live, err := s.engineWith(ctx, userID, toSrsConfig(config),
+		srs.WithWorkload(workload),
+		srs.WithLearningSteps(srs.DefaultLearningSteps()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	leechPolicy, err := s.GetLeechPolicy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	interview, err := s.GetInterviewDeadline(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	easePolicy, err := s.GetInitialEasePolicy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	related, err := s.GetRelatedCreditPolicy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 之前匯入過的 submission (Extension 可能定時重複送整包)
+	seen, err := s.repo.ListExternalIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := s.GetStatusGradeMapping(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	windowMinutes, err := s.GetSessionWindow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	grading, err := s.GetGradingPolicy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &importSession{
+		userID:     userID,
+		engines:    replayEngines{imported: imported, live: live},
+		workload:   workload,
+		leech:      leechPolicy,
+		interview:  interview,
+		easePolicy: easePolicy,
+		config:     config,
+		related:    related,
+		seen:       seen,
+		statuses:   statuses,
+		window:     time.Duration(windowMinutes) * time.Minute,
+		hardAfter:  grading.HardAfterWrongAttempts,
+	}, nil
+}
+
+// historyBatch 依題目分組後的匯入內容
+type historyBatch struct {
+	slugs   []string                // 依第一次出現的順序
+	items   map[string][]replayItem // Key=Slug
+	fresh   map[string]int          // 每題有幾筆沒匯入過的紀錄
+	skipped int                     // 之前已經匯入過的紀錄
+	ignored int                     // 依狀態對應表略過的紀錄
 }
 
-func (s *reviewServiceImpl) ImportHistory(ctx context.Context, userID string, req ImportSubmissionRequest) error {
-	// 1. 資料前處理：按時間排序 (從舊到新)
-	sort.Slice(req.History, func(i, j int) bool {
-		return req.History[i].Timestamp < req.History[j].Timestamp
+// groupHistory 把歷史紀錄按時間排序 (從舊到新) 後依題目分組
+// 同一包裡重複的 submission 與對應表裡要略過的狀態 (例如 Compile Error) 不會進入回放
+func groupHistory(history []HistoryItem, seen map[int64]bool, statuses entity.StatusGradeMapping) historyBatch {
+	history = slices.Clone(history)
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].Timestamp < history[j].Timestamp
 	})
 
-	// 用 Map 分組： Key=Slug, Value=List of items
-	historyBySlug := make(map[string][]replayItem)
+	batch := historyBatch{
+		items: make(map[string][]replayItem),
+		fresh: make(map[string]int),
+	}
+	inBatch := make(map[int64]bool)
+
+	for _, item := range history {
+		if item.SubmissionID != 0 {
+			if inBatch[item.SubmissionID] {
+				continue // 同一包裡重複的 submission
+			}
+			inBatch[item.SubmissionID] = true
+		}
+
+		grade := gradeForStatus(statuses, item.Status)
+		if grade == entity.GradeIgnore {
+			batch.ignored++
+			continue
+		}
 
-	for _, item := range req.History {
-		historyBySlug[item.Slug] = append(historyBySlug[item.Slug], replayItem{
-			Timestamp: time.Unix(item.Timestamp, 0),
-			Status:    item.Status,
-			Title:     item.Title,
+		if item.SubmissionID != 0 && seen[item.SubmissionID] {
+			batch.skipped++
+		} else {
+			batch.fresh[item.Slug]++
+		}
+
+		if _, ok := batch.items[item.Slug]; !ok {
+			batch.slugs = append(batch.slugs, item.Slug)
+		}
+		batch.items[item.Slug] = append(batch.items[item.Slug], replayItem{
+			SubmissionID: item.SubmissionID,
+			Timestamp:    time.Unix(item.Timestamp, 0),
+			Grade:        grade,
+			Status:       item.Status,
+			Title:        item.Title,
+			Difficulty:   item.Difficulty,
+			Category:     item.Category,
 		})
 	}
+	return batch
+}
 
-	// 2. 逐題處理
-	for slug, items := range historyBySlug {
-		// A. 確保題目存在 (Lazy Loading)
-		// 取第一筆紀錄的 Title 來當作題目名稱
-		questionID, err := s.ensureQuestionExists(ctx, slug, items[0].Title)
+// slugReplay 單題回放的結果 (還沒寫入 DB)
+type slugReplay struct {
+	current   *entity.UserQuestionStats // 匯入前的狀態，nil 代表還沒有
+	final     entity.UserQuestionStats
+	logs      []entity.SubmissionLog // 只含之前沒匯入過的
+	snapshots []entity.ReviewSnapshot
+	// 與既有 Log 時間相同的紀錄 (沒有 submission id 的舊匯入)，視為重複
+	duplicates int
+}
+
+// importSlug 回放並寫入單題的紀錄
+func (s *reviewServiceImpl) importSlug(ctx context.Context, session *importSession, slug string, items []replayItem) (*slugReplay, error) {
+	// A. 確保題目存在 (Lazy Loading)，並補上難度與分類
+	question, err := s.ensureQuestionExists(ctx, slug, items)
+	if err != nil {
+		return nil, err
+	}
+
+	// 從讀取目前狀態到寫入之間，不讓同一位使用者的即時評分或另一個匯入插進來
+	unlock, err := s.repo.LockUser(ctx, session.userID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	current, err := s.repo.GetUserStats(ctx, session.userID, question.ID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetQuestionLogs(ctx, session.userID, question.ID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.repo.ListCardEvents(ctx, session.userID, question.ID)
+	if err != nil {
+		return nil, err
+	}
+	replay := s.replaySlug(session, question, current, existing, events, items)
+	if err := session.workload.err(); err != nil {
+		return nil, err
+	}
+
+	// D. 寫入最終狀態
+	if err := s.repo.UpsertUserStats(ctx, replay.final); err != nil {
+		return nil, err
+	}
+
+	// E. 批次寫入 Logs (只寫之前沒匯入過的)
+	if len(replay.logs) > 0 {
+		if err := s.repo.BatchCreateLogs(ctx, replay.logs); err != nil {
+			return nil, err
+		}
+	}
+
+	// F. 每次練習後的狀態快照 (給圖表用)
+	if err := s.repo.SaveSnapshots(ctx, replay.snapshots); err != nil {
+		return nil, err
+	}
+
+	return &replay, nil
+}
+
+// replaySlug 計算單題匯入後的狀態，不寫入 DB (dry run 也使用)
+// existing 是這題已經有的 Logs (即時練習與之前的匯入)，與新紀錄合併後從頭依時間回放，
+// 兩邊的練習都會反映在排程上，不會互相覆蓋；events 在回放中依時間重新套用
+func (s *reviewServiceImpl) replaySlug(session *importSession, question *entity.Question, current *entity.UserQuestionStats, existing []entity.SubmissionLog, events []entity.CardEvent, items []replayItem) slugReplay {
+	// B. 去除重複：之前匯入過的 submission，以及與既有 Log 時間相同的紀錄 (沒有 submission id 的舊匯入)
+	loggedAt := make(map[int64]bool, len(existing))
+	for _, log := range existing {
+		loggedAt[log.Date.Unix()] = true
+	}
+	duplicates := 0
+	items = slices.DeleteFunc(slices.Clone(items), func(item replayItem) bool {
+		if item.SubmissionID != 0 && session.seen[item.SubmissionID] {
+			return true // 已經算在 Skipped 裡
+		}
+		if loggedAt[item.Timestamp.Unix()] {
+			duplicates++
+			return true
+		}
+		return false
+	})
+
+	// C. 合併成一條時間軸：既有的紀錄依原始狀態重新評分，與新紀錄放在一起之後才分組
+	// (跨兩次同步的連續提交，例如上次同步的 WA WA WA 與這次的 AC，仍算同一次練習)
+	merged := append(itemsFromLogs(existing, session.statuses), items...)
+	slices.SortStableFunc(merged, func(a, b replayItem) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	sessions := collapseSessions(merged, session.window, session.hardAfter)
+
+	seed := seedFor(session.easePolicy, session.config, question)
+	initial := entity.UserQuestionStats{
+		UserID:     session.userID,
+		QuestionID: question.ID,
+		EaseFactor: seed.EaseFactor,
+		Status:     "NEW",
+	}
+	// 有狀態卻沒有 Log (例如手動搬移的舊資料)：無從重建，把新紀錄接在目前狀態之後
+	// 目前狀態已經包含之前的事件，不再重複套用
+	if current != nil && len(existing) == 0 {
+		initial = *current
+		events = nil
+	}
+
+	if current != nil && current.Status != "SUSPENDED" {
+		session.workload.remove(current.NextReviewAt)
+	}
+
+	// D. 執行回放演算法 (Replay) 計算最終狀態
+	final, logs, snapshots := replaySessions(session.engines, session.replayOptions(question, seed, events), initial, sessions)
+	// 同一批匯入的題目在 Fuzz 範圍內互相錯開 (回放前已經把這題原本的到期日移除)
+	if final.Status != "SUSPENDED" {
+		session.workload.add(final.NextReviewAt)
+	}
+
+	return slugReplay{current: current, final: final, logs: logs, snapshots: snapshots, duplicates: duplicates}
+}
+
+// GetSyncCursor 沒同步過時回傳零值 (Extension 會抓全部的紀錄)
+func (s *reviewServiceImpl) GetSyncCursor(ctx context.Context, userID string) (*entity.SyncCursor, error) {
+	cursor, err := s.repo.GetSyncCursor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if cursor == nil {
+		return &entity.SyncCursor{UserID: userID}, nil
+	}
+	return cursor, nil
+}
+
+func (s *reviewServiceImpl) GetTodayTasks(ctx context.Context, userID string, query TaskQuery) ([]entity.QuestionTask, error) {
+	// 預設 limit 為 3
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 3
+	}
+	now := s.clock.Now()
+
+	// 一般情況：依相對逾期排序 (面試模式則依面試日期)，交給 DB 處理
+	if query.SortBy != TaskSortRetrievability && query.MaxRetrievability <= 0 {
+		interview, err := s.GetInterviewDeadline(ctx, userID)
 		if err != nil {
-			continue
+			return nil, err
 		}
 
-		// B. 執行回放演算法 (Replay) 計算最終狀態
-		finalStats, logsToInsert := s.replayHistory(userID, questionID, items)
+		var tasks []entity.QuestionTask
+		if interview != nil {
+			tasks, err = s.repo.GetDeadlineTasks(ctx, userID, *interview, limit)
+		} else {
+			tasks, err = s.repo.GetDailyTasks(ctx, userID, limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+		fillRetrievability(tasks, now)
+		return tasks, nil
+	}
 
-		// C. 寫入最終狀態
-		if err := s.repo.UpsertUserStats(ctx, finalStats); err != nil {
-			return err
+	// 依記憶保留率篩選/排序：需要所有練習過的題目，在這裡計算
+	all, err := s.repo.ListQuestionTasks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	fillRetrievability(all, now)
+
+	tasks := all[:0]
+	for _, t := range all {
+		if query.MaxRetrievability > 0 && t.Retrievability >= query.MaxRetrievability {
+			continue
 		}
+		tasks = append(tasks, t)
+	}
 
-		// D. 批次寫入 Logs
-		if len(logsToInsert) > 0 {
-			if err := s.repo.BatchCreateLogs(ctx, logsToInsert); err != nil {
-				return err
-			}
+	if query.SortBy == TaskSortRetrievability {
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return tasks[i].Retrievability < tasks[j].Retrievability
+		})
+	} else {
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return tasks[i].OverdueByDays > tasks[j].OverdueByDays
+		})
+	}
+
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+func (s *reviewServiceImpl) GetQuestionStats(ctx context.Context, userID, questionID string) (*entity.QuestionStats, error) {
+	stats, err := s.repo.GetUserStats(ctx, userID, questionID)
+	if err != nil || stats == nil {
+		return nil, err
+	}
+
+	return &entity.QuestionStats{
+		UserQuestionStats: *stats,
+		Retrievability:    srs.Retrievability(memoryStateOf(*stats), s.clock.Now()),
+	}, nil
+}
+
+// GetQuestionLogs 取得單題的練習紀錄 (含回放時保存的排程計算過程)
+func (s *reviewServiceImpl) GetQuestionLogs(ctx context.Context, userID, questionID string) ([]entity.SubmissionLog, error) {
+	return s.repo.GetQuestionLogs(ctx, userID, questionID)
+}
+
+// GetQuestionTimeline 取得單題每次練習後的狀態快照 (按時間從舊到新)
+func (s *reviewServiceImpl) GetQuestionTimeline(ctx context.Context, userID, questionID string) ([]entity.ReviewSnapshot, error) {
+	return s.repo.GetSnapshots(ctx, userID, questionID)
+}
+
+// Helper: 把 srs 的計算過程轉成可以存進 DB / 回傳給前端的格式
+func traceOf(steps []srs.TraceStep) []entity.TraceStep {
+	if len(steps) == 0 {
+		return nil
+	}
+	trace := make([]entity.TraceStep, len(steps))
+	for i, step := range steps {
+		trace[i] = entity.TraceStep{
+			Step:   step.Step,
+			Input:  step.Input,
+			Factor: step.Factor,
+			Output: step.Output,
+			Note:   step.Note,
 		}
 	}
+	return trace
+}
+
+// Helper: 為每個任務填入目前的記憶保留率
+func fillRetrievability(tasks []entity.QuestionTask, now time.Time) {
+	for i := range tasks {
+		tasks[i].Retrievability = srs.Retrievability(srs.MemoryState{
+			IntervalDays:    tasks[i].IntervalDays,
+			IntervalMinutes: tasks[i].IntervalMinutes,
+			Stability:       tasks[i].Stability,
+			LastReviewedAt:  tasks[i].LastReviewedAt,
+		}, now)
+	}
+}
+
+// Helper: 距離上次練習的實際天數 (沒練習過回傳 0)
+func elapsedDays(lastReviewedAt, now time.Time) float64 {
+	if lastReviewedAt.IsZero() || !now.After(lastReviewedAt) {
+		return 0
+	}
+	return now.Sub(lastReviewedAt).Hours() / 24.0
+}
+
+// Helper: 把 DB 的狀態轉成 srs 需要的 MemoryState
+func memoryStateOf(stats entity.UserQuestionStats) srs.MemoryState {
+	return srs.MemoryState{
+		IntervalDays:    stats.IntervalDays,
+		IntervalMinutes: stats.IntervalMinutes,
+		Stability:       stats.Stability,
+		LastReviewedAt:  stats.LastReviewedAt,
+	}
+}
 
-	return nil
+func (s *reviewServiceImpl) GetAlgorithm(ctx context.Context, userID string) (string, error) {
+	algorithm, err := s.repo.GetUserAlgorithm(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if algorithm == "" {
+		return srs.DefaultAlgorithm, nil
+	}
+	return algorithm, nil
 }
 
-func (s *reviewServiceImpl) GetTodayTasks(ctx context.Context, userID string) ([]entity.QuestionTask, error) {
-	// 設定 limit 為 3 (根據你的需求，也可以做成參數傳入)
-	return s.repo.GetDailyTasks(ctx, userID, 3)
+func (s *reviewServiceImpl) SetAlgorithm(ctx context.Context, userID, algorithm string) error {
+	// 先確認演算法存在，避免存進無效的名稱
+	if _, err := srs.NewScheduler(algorithm); err != nil {
+		return err
+	}
+	return s.repo.SetUserAlgorithm(ctx, userID, algorithm)
+}
+
+// Helper: 以使用者選用的 Scheduler 與排程設定建立 Engine
+// Fuzz 使用 (user, question, review count) 的雜湊，重跑匯入會得到相同的排程
+// 額外的 opts (負載平衡、學習步驟) 由呼叫端決定
+func (s *reviewServiceImpl) engineFor(ctx context.Context, userID string, opts ...srs.EngineOption) (*srs.Engine, error) {
+	config, err := s.GetSchedulerConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.engineWith(ctx, userID, toSrsConfig(config), opts...)
+}
+
+// Helper: 以指定的排程設定建立 Engine (預覽尚未儲存的設定時使用)
+func (s *reviewServiceImpl) engineWith(ctx context.Context, userID string, config srs.SchedulerConfig, opts ...srs.EngineOption) (*srs.Engine, error) {
+	scheduler, err := s.schedulerFor(ctx, userID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]srs.EngineOption{
+		srs.WithClock(s.clock),
+		srs.WithFuzz(srs.HashFuzz{}),
+		srs.WithConfig(config),
+	}, opts...)
+	return srs.NewEngine(scheduler, opts...), nil
+}
+
+// Helper: 取得使用者選用的 Scheduler (沒設定則用預設)
+// 改良版 SM-2 如果有 optimizer 擬合過的參數，就套用該參數 (排程設定裡手動指定的倍率優先)
+// 原版 SM-2 只套用 EF 底限
+func (s *reviewServiceImpl) schedulerFor(ctx context.Context, userID string, config srs.SchedulerConfig) (srs.Scheduler, error) {
+	algorithm, err := s.GetAlgorithm(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm == srs.AlgorithmLeTrackerSM2 {
+		scheduler := srs.LeTrackerSM2{Config: config}
+		params, err := s.repo.GetSchedulerParams(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if params != nil {
+			scheduler.Params = srs.LeTrackerParams{
+				HardModifier:   params.HardModifier,
+				EasyBonus:      params.EasyBonus,
+				RetentionBonus: params.RetentionBonus,
+			}
+		}
+		return scheduler, nil
+	}
+	if algorithm == srs.AlgorithmSM2 {
+		return srs.SM2{Config: config}, nil
+	}
+
+	return srs.NewScheduler(algorithm)
 }
 
 // Helper: 確保題目存在，不存在則建立
-func (s *reviewServiceImpl) ensureQuestionExists(ctx context.Context, slug string, title string) (string, error) {
-	// 1. 查 DB
+func (s *reviewServiceImpl) ensureQuestionExists(ctx context.Context, slug string, items []replayItem) (*entity.Question, error) {
+	newQ := questionFromItems(slug, items)
+	difficulty, category := newQ.Difficulty, newQ.Category
+
+	// 1. 查 DB (沒找到以外的錯誤直接回傳，不能當成新題重複建立)
 	q, err := s.repo.GetQuestionBySlug(ctx, slug)
+	if err != nil && !errors.Is(err, repository.ErrQuestionNotFound) {
+		return nil, err
+	}
 	if err == nil {
-		return q.ID, nil
+		// 舊的匯入沒有寫入難度/分類，有資料就補上
+		if (q.Difficulty == "" && difficulty != "") || (q.Category == "" && category != "") {
+			if err := s.repo.UpdateQuestionMeta(ctx, q.ID, difficulty, category); err != nil {
+				return nil, err
+			}
+			if q.Difficulty == "" {
+				q.Difficulty = difficulty
+			}
+			if q.Category == "" {
+				q.Category = category
+			}
+		}
+		return q, nil
 	}
 
 	// 2. 沒找到 -> 建立
-	newQ := entity.Question{
-		Slug:          slug,
-		Title:         title,
-		IsNeetcode150: true, // 匯入的預設為 true
+	id, err := s.repo.CreateQuestion(ctx, newQ)
+	if err != nil {
+		return nil, err
 	}
-	// log.Println(newQ)
-	return s.repo.CreateQuestion(ctx, newQ)
+	newQ.ID = id
+	return &newQ, nil
 }
 
-// Helper: 核心回放邏輯
-func (s *reviewServiceImpl) replayHistory(userID, questionID string, items []replayItem) (entity.UserQuestionStats, []entity.SubmissionLog) {
-	// 初始化狀態
-	currentStats := entity.UserQuestionStats{
-		UserID:       userID,
-		QuestionID:   questionID,
-		IntervalDays: 0,
-		EaseFactor:   2.5,
-		Streak:       0,
-		Status:       "NEW",
+// lookupQuestion 與 ensureQuestionExists 相同，但不寫入 DB
+// 題目不存在時回傳還沒儲存的題目 (ID 為空) 與 true，其他錯誤直接回傳
+func (s *reviewServiceImpl) lookupQuestion(ctx context.Context, slug string, items []replayItem) (*entity.Question, bool, error) {
+	newQ := questionFromItems(slug, items)
+	q, err := s.repo.GetQuestionBySlug(ctx, slug)
+	if errors.Is(err, repository.ErrQuestionNotFound) {
+		return &newQ, true, nil
 	}
+	if err != nil {
+		return nil, false, err
+	}
+	if q.Difficulty == "" {
+		q.Difficulty = newQ.Difficulty
+	}
+	if q.Category == "" {
+		q.Category = newQ.Category
+	}
+	return q, false, nil
+}
 
-	var logs []entity.SubmissionLog
-	var lastReviewDate time.Time
-
-	for i, item := range items {
-		// 1. 準備 Log 物件
-		mastery := 2 // 預設 Accepted = Good
-		if item.Status != "Accepted" {
-			mastery = 0 // Failed
+// questionFromItems 由匯入的紀錄組出題目：取第一筆紀錄的 Title 來當作題目名稱，難度/分類取第一個有值的
+func questionFromItems(slug string, items []replayItem) entity.Question {
+	q := entity.Question{
+		Slug:          slug,
+		Title:         items[0].Title,
+		IsNeetcode150: true, // 匯入的預設為 true
+	}
+	for _, item := range items {
+		if q.Difficulty == "" {
+			q.Difficulty = item.Difficulty
 		}
+		if q.Category == "" {
+			q.Category = item.Category
+		}
+	}
+	return q
+}
+
+// Helper: 核心回放邏輯，依序回放每一次練習 (sessions 需按時間排序)
+// 排程交給 srs.Engine.Timeline，這裡只負責 Log、leech 與每次練習後的快照
+// opts.events 在對應的時間點套用：事件之間的練習一起交給 Timeline，遇到事件就先套用再繼續
+// initial 是回放的起點，通常為新題的初始狀態
+func replaySessions(engines replayEngines, opts replayOptions, initial entity.UserQuestionStats, sessions []attemptSession) (entity.UserQuestionStats, []entity.SubmissionLog, []entity.ReviewSnapshot) {
+	currentStats := initial
+	events := opts.events
+	logs := make([]entity.SubmissionLog, 0, len(sessions))
+	var snapshots []entity.ReviewSnapshot
 
-		log := entity.SubmissionLog{
-			UserID:       userID,
-			QuestionID:   questionID,
-			Status:       "SOLVED",
-			MasteryLevel: mastery,
-			Date:         item.Timestamp,
+	for start := 0; ; {
+		// 先套用下一次練習之前發生的事件
+		for len(events) > 0 && (start == len(sessions) || events[0].At.Before(sessions[start].at())) {
+			applyCardEvent(&currentStats, opts, events[0])
+			events = events[1:]
 		}
-		if mastery == 0 {
-			log.Status = "FAILED"
+		if start == len(sessions) {
+			break
 		}
-		logs = append(logs, log)
 
-		// 2. 計算 ActualDays (距離上次的時間)
-		actualDays := 0.0
-		if i > 0 {
-			actualDays = item.Timestamp.Sub(lastReviewDate).Hours() / 24.0
+		end := start + 1
+		for end < len(sessions) && (len(events) == 0 || !events[0].At.Before(sessions[end].at())) {
+			end++
+		}
+		segmentLogs, segmentSnapshots := replaySegment(engines, opts, &currentStats, sessions[start:end])
+		logs = append(logs, segmentLogs...)
+		snapshots = append(snapshots, segmentSnapshots...)
+		start = end
+	}
+
+	return currentStats, logs, snapshots
+}
+
+// replaySegment 回放一段中間沒有事件的練習，直接更新 currentStats
+// 連續的匯入提交與連續的即時練習分別交給對應的 Engine，階段與學習步驟在兩者之間延續
+func replaySegment(engines replayEngines, opts replayOptions, currentStats *entity.UserQuestionStats, sessions []attemptSession) ([]entity.SubmissionLog, []entity.ReviewSnapshot) {
+	userID, questionID := currentStats.UserID, currentStats.QuestionID
+
+	card := cardStateOf(*currentStats)
+	var timeline []srs.Snapshot
+	for start := 0; start < len(sessions); {
+		manual := sessions[start].manual()
+		end := start + 1
+		for end < len(sessions) && sessions[end].manual() == manual {
+			end++
 		}
 
-		// 設定第一筆的時間錨點
-		if i == 0 {
-			lastReviewDate = item.Timestamp
+		events := make([]srs.ReviewEvent, 0, end-start)
+		for _, session := range sessions[start:end] {
+			events = append(events, srs.ReviewEvent{At: session.at(), Grade: session.grade})
 		}
 
+		engine := engines.imported
 		// [過濾機制]：如果同一天刷多次 (間隔 < 12小時)，跳過 SRS 計算，但 Log 照記
-		if i > 0 && actualDays < 0.5 {
-			continue
+		// 即時練習當下每次都有排程 (學習步驟本來就在同一天內)，不過濾
+		minGap := 12 * time.Hour
+		if manual {
+			engine, minGap = engines.live, 0
 		}
+		run := engine.Timeline(card, events, srs.TimelineOptions{
+			UserID:          userID,
+			QuestionID:      questionID,
+			MinGap:          minGap,
+			Deadline:        opts.deadline,
+			Explain:         true, // 回放的計算過程存進 Log，方便事後稽核
+			SecondIntervals: opts.seed.SecondIntervals,
+		})
+		timeline = append(timeline, run...)
+		card = run[len(run)-1].State
+		start = end
+	}
 
-		// 3. 呼叫 SRS
-		srsInput := srs.ReviewInput{
-			CurrentInterval: currentStats.IntervalDays,
-			CurrentEF:       currentStats.EaseFactor,
-			Repetitions:     currentStats.Streak,
-			Grade:           mastery,
-			ActualDays:      actualDays, // 傳入實際天數以觸發 Bonus
+	logs := make([]entity.SubmissionLog, 0, len(sessions))
+	var snapshots []entity.ReviewSnapshot
+	for i, snapshot := range timeline {
+		// 每次提交都寫 Log，存原始狀態與這筆提交自己的評分 (整次練習的評分在回放時重新推導)
+		// 最後一筆附上這次練習的排程過程
+		session := sessions[i]
+		for j, item := range session.items {
+			if item.Logged {
+				continue
+			}
+			log := entity.SubmissionLog{
+				UserID:           userID,
+				QuestionID:       questionID,
+				Status:           "FAILED",
+				SubmissionStatus: item.Status,
+				MasteryLevel:     item.Grade,
+				Date:             item.Timestamp,
+				ExternalID:       item.SubmissionID,
+			}
+			// 對應表算作 AC 的提交才是 SOLVED (TLE 之類的 Hard 仍是沒解出來)
+			if item.Grade >= 2 {
+				log.Status = "SOLVED"
+			}
+			if j == len(session.items)-1 && !snapshot.Skipped {
+				log.Trace = traceOf(snapshot.Output.Trace)
+			}
+			logs = append(logs, log)
+		}
+		if snapshot.Skipped {
+			continue
 		}
 
-		srsOutput := srs.CalculateNextReview(srsInput)
+		// 更新狀態
+		suspended := currentStats.Status == "SUSPENDED"
+		applyCardState(currentStats, snapshot.State)
+		currentStats.Status = determineStatus(snapshot.State.Phase, snapshot.State.Repetitions)
+		if suspended {
+			currentStats.Status = "SUSPENDED"
+		}
+		if snapshot.Output.Lapsed {
+			applyLapse(currentStats, opts.leech)
+		}
+		snapshots = append(snapshots, snapshotOf(*currentStats, snapshot.Event.Grade))
+	}
 
-		// 4. 更新狀態
-		currentStats.IntervalDays = srsOutput.Interval
-		currentStats.EaseFactor = srsOutput.EaseFactor
-		currentStats.Streak = srsOutput.Repetitions
-		currentStats.Status = determineStatus(srsOutput.Repetitions)
+	return logs, snapshots
+}
 
-		lastReviewDate = item.Timestamp
+// applyCardEvent 回放時套用一個練習以外的事件 (規則與當下的操作相同)
+// 暫停只由回放中的 lapse 決定，解除暫停與重設也要依時間套用，否則之前的 lapse 又會把題目暫停回去
+func applyCardEvent(stats *entity.UserQuestionStats, opts replayOptions, event entity.CardEvent) {
+	switch event.Kind {
+	case entity.CardEventRelated:
+		if next, ok := relatedDue(opts.related, *stats, event.Weight, event.Grade, event.At, opts.maxInterval, opts.deadline); ok {
+			stats.NextReviewAt = next
+		}
+	case entity.CardEventUnsuspend:
+		unsuspend(stats, event.At)
+	case entity.CardEventReset:
+		resetCard(stats, opts.seed, event.At)
 	}
+}
 
-	// 計算最終的 NextReviewAt
-	currentStats.LastReviewedAt = lastReviewDate
-	currentStats.NextReviewAt = lastReviewDate.AddDate(0, 0, currentStats.IntervalDays)
+// applyCardState 把 srs 的 CardState 寫回 DB 的狀態 (NextReviewAt 以該次練習的時間為錨點)
+func applyCardState(stats *entity.UserQuestionStats, card srs.CardState) {
+	stats.IntervalDays = card.IntervalDays
+	stats.IntervalMinutes = card.IntervalMinutes
+	stats.EaseFactor = card.EaseFactor
+	stats.Streak = card.Repetitions
+	stats.LearningStep = card.Step
+	stats.Stability = card.Stability
+	stats.Difficulty = card.Difficulty
+	stats.ReviewCount = card.ReviewCount
+	stats.NextReviewAt = card.NextReviewAt
+	stats.LastReviewedAt = card.LastReviewedAt
+}
 
-	return currentStats, logs
+// snapshotOf 一次練習之後的狀態快照 (給圖表用)
+func snapshotOf(stats entity.UserQuestionStats, grade int) entity.ReviewSnapshot {
+	return entity.ReviewSnapshot{
+		UserID:          stats.UserID,
+		QuestionID:      stats.QuestionID,
+		ReviewedAt:      stats.LastReviewedAt,
+		Grade:           grade,
+		Status:          stats.Status,
+		Streak:          stats.Streak,
+		EaseFactor:      stats.EaseFactor,
+		IntervalDays:    stats.IntervalDays,
+		IntervalMinutes: stats.IntervalMinutes,
+		Stability:       stats.Stability,
+		Difficulty:      stats.Difficulty,
+		NextReviewAt:    stats.NextReviewAt,
+	}
 }
 
-func determineStatus(streak int) string {
+func determineStatus(phase string, streak int) string {
+	// 學習步驟中 (分鐘級間隔)
+	switch phase {
+	case srs.PhaseLearning:
+		return "LEARNING"
+	case srs.PhaseRelearning:
+		return "RELEARNING"
+	}
+
 	if streak == 0 {
 		return "LEARNING"
 	} else if streak > 5 {
@@ -281,3 +1208,18 @@ func determineStatus(streak int) string {
 	}
 	return "REVIEW"
 }
+
+// phaseOf 由 DB 的狀態推回 Engine 的階段
+// 注意 LEARNING 有兩種：學習步驟中 (interval_days = 0) 與剛答錯、以天為單位的複習
+func phaseOf(stats *entity.UserQuestionStats) string {
+	switch {
+	case stats.Status == "" || stats.Status == "NEW":
+		return srs.PhaseNew
+	case stats.Status == "RELEARNING":
+		return srs.PhaseRelearning
+	case stats.Status == "LEARNING" && stats.IntervalDays == 0:
+		return srs.PhaseLearning
+	default:
+		return srs.PhaseReview
+	}
+}
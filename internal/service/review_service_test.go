@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestImportMergesBurstAcrossSyncs(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	item := func(id int64, minutes int, status string) HistoryItem {
+		return HistoryItem{Title: "Two Sum", Slug: "two-sum", Status: status, Timestamp: start.Add(time.Duration(minutes) * time.Minute).Unix(), SubmissionID: id}
+	}
+
+	// 上次同步送了 WA WA，這次 Extension 重送第一筆並帶上 AC
+	repo := &fakeRepo{}
+	svc := newTestService(repo, now)
+	if _, err := svc.ImportHistory(ctx, "u", ImportSubmissionRequest{History: []HistoryItem{item(1, 0, "Wrong Answer"), item(2, 10, "Wrong Answer")}}); err != nil {
+		t.Fatalf("first ImportHistory() error = %v", err)
+	}
+	result, err := svc.ImportHistory(ctx, "u", ImportSubmissionRequest{History: []HistoryItem{item(1, 0, "Wrong Answer"), item(3, 20, "Accepted")}})
+	if err != nil {
+		t.Fatalf("second ImportHistory() error = %v", err)
+	}
+	if result.Imported != 1 || result.Skipped != 1 || len(repo.logs) != 3 {
+		t.Errorf("second sync imported %d, skipped %d, %d logs total, want 1, 1 and 3", result.Imported, result.Skipped, len(repo.logs))
+	}
+
+	// 與一次匯入整段的結果相同：WA WA AC 算同一次練習 (Good)
+	once := &fakeRepo{}
+	if _, err := newTestService(once, now).ImportHistory(ctx, "u", ImportSubmissionRequest{History: []HistoryItem{item(1, 0, "Wrong Answer"), item(2, 10, "Wrong Answer"), item(3, 20, "Accepted")}}); err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+	got, want := repo.stats["q-1"], once.stats["q-1"]
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stats after two syncs = %+v, want %+v", got, want)
+	}
+	if got.ReviewCount != 1 {
+		t.Errorf("ReviewCount = %d, want the burst counted as one session", got.ReviewCount)
+	}
+}
+
+func TestImportSkipsLoggedTimestamps(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	repo := &fakeRepo{}
+	svc := newTestService(repo, now)
+
+	// 沒有 submission id 的紀錄只能靠時間去除重複
+	req := ImportSubmissionRequest{History: []HistoryItem{
+		{Title: "Two Sum", Slug: "two-sum", Status: "Accepted", Timestamp: time.Date(2026, 1, 10, 20, 0, 0, 0, time.UTC).Unix()},
+	}}
+	if _, err := svc.ImportHistory(ctx, "u", req); err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+	before := repo.stats["q-1"]
+
+	result, err := svc.ImportHistory(ctx, "u", req)
+	if err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+	if result.Imported != 0 || result.Skipped != 1 || len(repo.logs) != 1 {
+		t.Errorf("re-import imported %d, skipped %d, %d logs total, want 0, 1 and 1", result.Imported, result.Skipped, len(repo.logs))
+	}
+	if after := repo.stats["q-1"]; !reflect.DeepEqual(after, before) {
+		t.Errorf("stats = %+v, want unchanged %+v", after, before)
+	}
+}
+
+func TestImportKeepsLiveLearningSteps(t *testing.T) {
+	ctx := context.Background()
+	reviewedAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{}
+	item := func(id int64, at time.Time) HistoryItem {
+		return HistoryItem{Title: "Two Sum", Slug: "two-sum", Status: "Accepted", Timestamp: at.Unix(), SubmissionID: id}
+	}
+
+	if _, err := newTestService(repo, reviewedAt).ImportHistory(ctx, "u", ImportSubmissionRequest{History: []HistoryItem{item(2, reviewedAt.AddDate(0, 0, -10))}}); err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+
+	// 即時練習：答錯進入重新學習，15 分鐘後答對進到第 2 步 (4 小時後)
+	again, good := 0, 2
+	if _, err := newTestService(repo, reviewedAt).ProcessReview(ctx, "u", ReviewRequest{QuestionID: "q-1", Grade: &again}); err != nil {
+		t.Fatalf("ProcessReview() error = %v", err)
+	}
+	if _, err := newTestService(repo, reviewedAt.Add(15*time.Minute)).ProcessReview(ctx, "u", ReviewRequest{QuestionID: "q-1", Grade: &good}); err != nil {
+		t.Fatalf("ProcessReview() error = %v", err)
+	}
+	live := repo.stats["q-1"]
+	if live.Status != "RELEARNING" || live.LearningStep != 1 {
+		t.Fatalf("live state = %s step %d, want RELEARNING step 1", live.Status, live.LearningStep)
+	}
+
+	// 之後同步到一筆更早的 submission：整題重新回放，即時練習照當下的學習步驟走
+	if _, err := newTestService(repo, reviewedAt.Add(time.Hour)).ImportHistory(ctx, "u", ImportSubmissionRequest{History: []HistoryItem{item(1, reviewedAt.AddDate(0, 0, -20))}}); err != nil {
+		t.Fatalf("ImportHistory() error = %v", err)
+	}
+	got := repo.stats["q-1"]
+	if got.Status != "RELEARNING" || got.LearningStep != 1 || got.IntervalMinutes != 240 {
+		t.Errorf("after re-import: %s step %d, %d minutes, want RELEARNING step 1, 240 minutes", got.Status, got.LearningStep, got.IntervalMinutes)
+	}
+	if want := reviewedAt.Add(15*time.Minute + 4*time.Hour); !got.NextReviewAt.Equal(want) {
+		t.Errorf("NextReviewAt = %v, want %v", got.NextReviewAt, want)
+	}
+	if got.ReviewCount != 4 {
+		t.Errorf("ReviewCount = %d, want both imports and both same-day live reviews", got.ReviewCount)
+	}
+}
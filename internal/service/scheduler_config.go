@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"letracker/internal/entity"
+	"letracker/pkg/srs"
+)
+
+// ErrInvalidSchedulerConfig 排程設定不合理
+var ErrInvalidSchedulerConfig = errors.New("invalid scheduler config")
+
+// previewSample 沒有指定題目時，預覽用的範例題目：已經複習過 3 次、間隔 10 天
+var previewSample = entity.UserQuestionStats{
+	Streak:       3,
+	EaseFactor:   2.5,
+	IntervalDays: 10,
+	Status:       "REVIEW",
+}
+
+// SchedulerPreview 套用新設定前後，範例題目在每種評分下的排程
+type SchedulerPreview struct {
+	Config   entity.SchedulerConfig
+	Sample   entity.UserQuestionStats
+	Outcomes []PreviewOutcome
+}
+
+// PreviewOutcome 單一評分的比較結果
+type PreviewOutcome struct {
+	Grade    int
+	Current  srs.ReviewOutput // 目前的設定
+	Proposed srs.ReviewOutput // 新的設定
+}
+
+func (s *reviewServiceImpl) GetSchedulerConfig(ctx context.Context, userID string) (entity.SchedulerConfig, error) {
+	config, err := s.repo.GetSchedulerConfig(ctx, userID)
+	if err != nil {
+		return entity.SchedulerConfig{}, err
+	}
+	if config == nil {
+		return fromSrsConfig(srs.DefaultSchedulerConfig()), nil
+	}
+	return withConfigDefaults(*config), nil
+}
+
+// SetSchedulerConfig 驗證並儲存排程設定 (沒給的欄位使用預設值)
+func (s *reviewServiceImpl) SetSchedulerConfig(ctx context.Context, userID string, config entity.SchedulerConfig) (entity.SchedulerConfig, error) {
+	config = withConfigDefaults(config)
+	if err := validateSchedulerConfig(config); err != nil {
+		return entity.SchedulerConfig{}, err
+	}
+
+	config.UserID = userID
+	if err := s.repo.SaveSchedulerConfig(ctx, config); err != nil {
+		return entity.SchedulerConfig{}, err
+	}
+	return config, nil
+}
+
+// PreviewSchedulerConfig 不儲存，只比較目前設定與新設定下一次複習的排程
+// questionID 有值時以該題目前的狀態為範例，否則使用 previewSample
+func (s *reviewServiceImpl) PreviewSchedulerConfig(ctx context.Context, userID string, config entity.SchedulerConfig, questionID string) (*SchedulerPreview, error) {
+	config = withConfigDefaults(config)
+	if err := validateSchedulerConfig(config); err != nil {
+		return nil, err
+	}
+
+	sample := previewSample
+	if questionID != "" {
+		stats, err := s.repo.GetUserStats(ctx, userID, questionID)
+		if err != nil {
+			return nil, err
+		}
+		if stats == nil {
+			return nil, ErrStatsNotFound
+		}
+		sample = *stats
+	}
+
+	current, err := s.engineFor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	proposed, err := s.engineWith(ctx, userID, toSrsConfig(config))
+	if err != nil {
+		return nil, err
+	}
+
+	// 兩邊使用相同的 Fuzz key 與時間，差異只來自設定
+	key := srs.FuzzKey{UserID: userID, QuestionID: sample.QuestionID, ReviewCount: sample.ReviewCount + 1}
+	now := s.clock.Now()
+
+	preview := &SchedulerPreview{Config: config, Sample: sample}
+	for grade := 0; grade <= 3; grade++ {
+		input := srs.ReviewInput{
+			CurrentInterval: sample.IntervalDays,
+			CurrentEF:       sample.EaseFactor,
+			Repetitions:     sample.Streak,
+			Grade:           grade,
+			Stability:       sample.Stability,
+			Difficulty:      sample.Difficulty,
+			ReviewedAt:      now,
+			Phase:           srs.PhaseReview,
+		}
+		preview.Outcomes = append(preview.Outcomes, PreviewOutcome{
+			Grade:    grade,
+			Current:  current.Review(key, input),
+			Proposed: proposed.Review(key, input),
+		})
+	}
+
+	return preview, nil
+}
+
+func validateSchedulerConfig(c entity.SchedulerConfig) error {
+	if c.MaximumInterval < 1 || c.MaximumInterval > 36500 {
+		return fmt.Errorf("%w: maximum_interval must be between 1 and 36500 days", ErrInvalidSchedulerConfig)
+	}
+	if c.MinimumEF < 1.0 || c.MinimumEF > 2.5 {
+		return fmt.Errorf("%w: minimum_ef must be between 1.0 and 2.5", ErrInvalidSchedulerConfig)
+	}
+	if c.HardModifier != 0 && (c.HardModifier < 0.1 || c.HardModifier > 1.0) {
+		return fmt.Errorf("%w: hard_modifier must be 0 (auto) or between 0.1 and 1.0", ErrInvalidSchedulerConfig)
+	}
+	if c.EasyBonus != 0 && (c.EasyBonus < 1.0 || c.EasyBonus > 3.0) {
+		return fmt.Errorf("%w: easy_bonus must be 0 (auto) or between 1.0 and 3.0", ErrInvalidSchedulerConfig)
+	}
+
+	prev := 1
+	for _, days := range c.SecondIntervals {
+		if days < prev || days > c.MaximumInterval {
+			return fmt.Errorf("%w: second_intervals must be increasing (hard <= good <= easy), at least 1 and within maximum_interval", ErrInvalidSchedulerConfig)
+		}
+		prev = days
+	}
+
+	if c.FuzzThresholdDays < 1 || c.FuzzThresholdDays > 365 {
+		return fmt.Errorf("%w: fuzz_threshold_days must be between 1 and 365", ErrInvalidSchedulerConfig)
+	}
+	if c.FuzzRange <= 0 || c.FuzzRange > 0.25 {
+		return fmt.Errorf("%w: fuzz_range must be greater than 0 and at most 0.25", ErrInvalidSchedulerConfig)
+	}
+	return nil
+}
+
+// Helper: 零值欄位補上預設值 (倍率維持 0 = 自動)
+func withConfigDefaults(c entity.SchedulerConfig) entity.SchedulerConfig {
+	filled := fromSrsConfig(toSrsConfig(c).WithDefaults())
+	filled.UserID = c.UserID
+	return filled
+}
+
+func toSrsConfig(c entity.SchedulerConfig) srs.SchedulerConfig {
+	return srs.SchedulerConfig{
+		MaximumInterval:   c.MaximumInterval,
+		MinimumEF:         c.MinimumEF,
+		HardModifier:      c.HardModifier,
+		EasyBonus:         c.EasyBonus,
+		SecondIntervals:   c.SecondIntervals,
+		FuzzThresholdDays: c.FuzzThresholdDays,
+		FuzzRange:         c.FuzzRange,
+	}
+}
+
+func fromSrsConfig(c srs.SchedulerConfig) entity.SchedulerConfig {
+	return entity.SchedulerConfig{
+		MaximumInterval:   c.MaximumInterval,
+		MinimumEF:         c.MinimumEF,
+		HardModifier:      c.HardModifier,
+		EasyBonus:         c.EasyBonus,
+		SecondIntervals:   c.SecondIntervals,
+		FuzzThresholdDays: c.FuzzThresholdDays,
+		FuzzRange:         c.FuzzRange,
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"letracker/internal/entity"
+	"letracker/pkg/srs"
+)
+
+func TestSchedulerForAppliesConfig(t *testing.T) {
+	config := srs.SchedulerConfig{MinimumEF: 1.8, EasyBonus: 1.4}
+	params := &entity.SchedulerParams{HardModifier: 0.7, EasyBonus: 1.2, RetentionBonus: 1.5}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		params    *entity.SchedulerParams
+		want      srs.Scheduler
+	}{
+		{name: "default algorithm", want: srs.LeTrackerSM2{Config: config}},
+		{
+			name:      "fitted parameters",
+			algorithm: srs.AlgorithmLeTrackerSM2,
+			params:    params,
+			want:      srs.LeTrackerSM2{Config: config, Params: srs.LeTrackerParams{HardModifier: 0.7, EasyBonus: 1.2, RetentionBonus: 1.5}},
+		},
+		{name: "original SM-2 gets the EF floor", algorithm: srs.AlgorithmSM2, params: params, want: srs.SM2{Config: config}},
+		{name: "Leitner", algorithm: srs.AlgorithmLeitner, want: srs.Leitner{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(&fakeRepo{algorithm: tt.algorithm, params: tt.params}, time.Now())
+			got, err := svc.schedulerFor(context.Background(), "u", config)
+			if err != nil {
+				t.Fatalf("schedulerFor() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("schedulerFor() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSchedulerConfig(t *testing.T) {
+	valid := withConfigDefaults(entity.SchedulerConfig{})
+	with := func(change func(*entity.SchedulerConfig)) entity.SchedulerConfig {
+		c := valid
+		change(&c)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		config  entity.SchedulerConfig
+		wantErr bool
+	}{
+		{name: "defaults", config: valid},
+		{name: "EF floor too low", config: with(func(c *entity.SchedulerConfig) { c.MinimumEF = 0.9 }), wantErr: true},
+		{name: "EF floor too high", config: with(func(c *entity.SchedulerConfig) { c.MinimumEF = 2.6 }), wantErr: true},
+		{name: "second intervals out of order", config: with(func(c *entity.SchedulerConfig) { c.SecondIntervals = [3]int{5, 3, 7} }), wantErr: true},
+		{name: "second interval above the maximum", config: with(func(c *entity.SchedulerConfig) { c.MaximumInterval = 6 }), wantErr: true},
+		{name: "fuzz range too wide", config: with(func(c *entity.SchedulerConfig) { c.FuzzRange = 0.5 }), wantErr: true},
+		{name: "manual multipliers", config: with(func(c *entity.SchedulerConfig) { c.HardModifier = 0.6; c.EasyBonus = 1.5 })},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchedulerConfig(tt.config)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("validateSchedulerConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSchedulerConfig) {
+				t.Errorf("error = %v, want ErrInvalidSchedulerConfig", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+// ErrInvalidSessionWindow 合併提交的時間窗不合理
+var ErrInvalidSessionWindow = errors.New("invalid session window")
+
+// DefaultSessionWindowMinutes 同一題前後兩次提交相隔 60 分鐘以內，視為同一次練習
+const DefaultSessionWindowMinutes = 60
+
+// maxSessionWindowMinutes 時間窗上限 (12 小時，超過就跟回放的同日過濾重疊了)
+const maxSessionWindowMinutes = 12 * 60
+
+// attemptSession 一次練習 (時間窗內的連續提交)
+type attemptSession struct {
+	items []replayItem
+	grade int // 由提交的模式推導出的評分
+}
+
+// at 以最後一次提交的時間作為這次練習的時間
+func (s attemptSession) at() time.Time {
+	return s.items[len(s.items)-1].Timestamp
+}
+
+// manual 即時練習 (不會與其他提交合併，所以只有一筆)
+func (s attemptSession) manual() bool {
+	return s.items[0].Manual
+}
+
+// collapseSessions 把前後相隔不超過 window 的連續提交合併成一次練習 (items 需按時間排序)
+// window 為 0 時不合併，每次提交各自依狀態對應表評分
+// 即時練習 (Manual) 不與其他提交合併，沿用使用者當時給的評分
+func collapseSessions(items []replayItem, window time.Duration, hardAfter int) []attemptSession {
+	var sessions []attemptSession
+	for i, item := range items {
+		if window > 0 && i > 0 && !item.Manual && !items[i-1].Manual &&
+			item.Timestamp.Sub(items[i-1].Timestamp) <= window {
+			last := &sessions[len(sessions)-1]
+			last.items = append(last.items, item)
+			continue
+		}
+		sessions = append(sessions, attemptSession{items: []replayItem{item}})
+	}
+
+	for i := range sessions {
+		if window > 0 && !sessions[i].items[0].Manual {
+			sessions[i].grade = sessionGrade(sessions[i].items, hardAfter)
+		} else {
+			sessions[i].grade = sessions[i].items[0].Grade
+		}
+	}
+	return sessions
+}
+
+// itemsFromLogs 把已經寫入的 Logs 還原成回放用的提交 (logs 需按時間排序)
+// 匯入的紀錄依原始狀態以目前的對應表重新評分，改了對應表、時間窗或 hard_after 之後重算就會反映；
+// 對應表改成略過的狀態不回放 (Log 保留)。舊版匯入沒有存原始狀態，沿用 Log 上的評分
+// 即時練習 (沒有 submission id 也沒有原始狀態) 標記為 Manual，沒有 submission id 的匯入仍以天為單位回放
+func itemsFromLogs(logs []entity.SubmissionLog, statuses entity.StatusGradeMapping) []replayItem {
+	items := make([]replayItem, 0, len(logs))
+	for _, log := range logs {
+		item := replayItem{
+			SubmissionID: log.ExternalID,
+			Timestamp:    log.Date,
+			Grade:        log.MasteryLevel,
+			Status:       log.SubmissionStatus,
+			Manual:       log.ExternalID == 0 && log.SubmissionStatus == "",
+			Logged:       true,
+
+			TimeTakenSeconds: log.TimeTakenSeconds,
+		}
+		if !item.Manual && item.Status != "" {
+			item.Grade = gradeForStatus(statuses, item.Status)
+			if item.Grade == entity.GradeIgnore {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// sessionGrade 由一次練習中的提交模式推導評分 (「AC」指對應表評分為 Good 以上的提交)：
+//   - 一次就 AC: Easy
+//   - AC 前錯了 hardAfter 次以上: Hard
+//   - 錯幾次後 AC: Good
+//   - 沒有 AC: 取各次提交中最高的評分 (例如 TLE 為 Hard，WA 為 Again)
+func sessionGrade(items []replayItem, hardAfter int) int {
+	best := 0
+	for failures, item := range items {
+		if item.Grade >= 2 {
+			switch {
+			case failures == 0:
+				return 3
+			case hardAfter > 0 && failures >= hardAfter:
+				return 1
+			default:
+				return 2
+			}
+		}
+		best = max(best, item.Grade)
+	}
+	return best
+}
+
+// GetSessionWindow 回傳合併提交的時間窗 (分鐘)，0 代表不合併
+func (s *reviewServiceImpl) GetSessionWindow(ctx context.Context, userID string) (int, error) {
+	minutes, err := s.repo.GetSessionWindow(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if minutes == nil {
+		return DefaultSessionWindowMinutes, nil
+	}
+	return *minutes, nil
+}
+
+// SetSessionWindow 設定合併提交的時間窗 (分鐘)，0 代表不合併
+func (s *reviewServiceImpl) SetSessionWindow(ctx context.Context, userID string, minutes int) error {
+	if minutes < 0 || minutes > maxSessionWindowMinutes {
+		return fmt.Errorf("%w: minutes must be between 0 and %d", ErrInvalidSessionWindow, maxSessionWindowMinutes)
+	}
+	return s.repo.SaveSessionWindow(ctx, userID, minutes)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"letracker/internal/entity"
+)
+
+func TestSessionGrade(t *testing.T) {
+	tests := []struct {
+		name      string
+		grades    []int
+		hardAfter int
+		want      int
+	}{
+		{name: "accepted on the first try", grades: []int{2}, hardAfter: 3, want: 3},
+		{name: "accepted after a wrong answer", grades: []int{0, 2}, hardAfter: 3, want: 2},
+		{name: "accepted after a TLE", grades: []int{1, 2}, hardAfter: 3, want: 2},
+		{name: "accepted after hardAfter failures", grades: []int{0, 0, 1, 2}, hardAfter: 3, want: 1},
+		{name: "hardAfter disabled", grades: []int{0, 0, 0, 0, 2}, hardAfter: 0, want: 2},
+		{name: "only wrong answers", grades: []int{0, 0}, hardAfter: 3, want: 0},
+		{name: "best failure without an accept", grades: []int{0, 1, 0}, hardAfter: 3, want: 1},
+		{name: "later submissions after the accept are ignored", grades: []int{2, 0}, hardAfter: 3, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]replayItem, len(tt.grades))
+			for i, grade := range tt.grades {
+				items[i] = replayItem{Grade: grade}
+			}
+			if got := sessionGrade(items, tt.hardAfter); got != tt.want {
+				t.Errorf("sessionGrade() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollapseSessions(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	tests := []struct {
+		name       string
+		items      []replayItem
+		window     time.Duration
+		wantSizes  []int
+		wantGrades []int
+	}{
+		{
+			name: "submissions inside the window form one session",
+			items: []replayItem{
+				{Timestamp: at(0), Grade: 0},
+				{Timestamp: at(20), Grade: 0},
+				{Timestamp: at(70), Grade: 2},
+			},
+			window:     time.Hour,
+			wantSizes:  []int{3},
+			wantGrades: []int{2},
+		},
+		{
+			name: "a gap longer than the window starts a new session",
+			items: []replayItem{
+				{Timestamp: at(0), Grade: 0},
+				{Timestamp: at(90), Grade: 2},
+			},
+			window:     time.Hour,
+			wantSizes:  []int{1, 1},
+			wantGrades: []int{0, 3},
+		},
+		{
+			name: "window 0 keeps the status grade of every submission",
+			items: []replayItem{
+				{Timestamp: at(0), Grade: 0},
+				{Timestamp: at(1), Grade: 2},
+			},
+			wantSizes:  []int{1, 1},
+			wantGrades: []int{0, 2},
+		},
+		{
+			name: "manual reviews are never merged and keep their grade",
+			items: []replayItem{
+				{Timestamp: at(0), Grade: 0},
+				{Timestamp: at(10), Grade: 1, Manual: true},
+				{Timestamp: at(20), Grade: 2},
+			},
+			window:     time.Hour,
+			wantSizes:  []int{1, 1, 1},
+			wantGrades: []int{0, 1, 3},
+		},
+		{
+			name:  "no submissions",
+			items: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := collapseSessions(tt.items, tt.window, 3)
+			if len(sessions) != len(tt.wantSizes) {
+				t.Fatalf("got %d sessions, want %d", len(sessions), len(tt.wantSizes))
+			}
+			for i, session := range sessions {
+				if len(session.items) != tt.wantSizes[i] {
+					t.Errorf("session %d has %d items, want %d", i, len(session.items), tt.wantSizes[i])
+				}
+				if session.grade != tt.wantGrades[i] {
+					t.Errorf("session %d grade = %d, want %d", i, session.grade, tt.wantGrades[i])
+				}
+			}
+		})
+	}
+}
+
+func TestItemsFromLogs(t *testing.T) {
+	date := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	logs := []entity.SubmissionLog{
+		{ExternalID: 1, Date: date, MasteryLevel: 0, SubmissionStatus: "Time Limit Exceeded"},
+		{ExternalID: 2, Date: date, MasteryLevel: 0, SubmissionStatus: "Compile Error"},
+		{ExternalID: 3, Date: date, MasteryLevel: 3},
+		{ExternalID: 0, Date: date, MasteryLevel: 1},
+		{ExternalID: 0, Date: date, MasteryLevel: 0, SubmissionStatus: "Accepted"},
+	}
+
+	items := itemsFromLogs(logs, DefaultStatusGradeMapping())
+	want := []replayItem{
+		{SubmissionID: 1, Timestamp: date, Grade: 1, Status: "Time Limit Exceeded", Logged: true}, // 依目前的對應表重新評分
+		{SubmissionID: 3, Timestamp: date, Grade: 3, Logged: true},                                // 舊版匯入沿用 Log 的評分
+		{SubmissionID: 0, Timestamp: date, Grade: 1, Logged: true, Manual: true},
+		{SubmissionID: 0, Timestamp: date, Grade: 2, Status: "Accepted", Logged: true}, // 沒有 submission id 的匯入不是即時練習
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(items), len(want))
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+}
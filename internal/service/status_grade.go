@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"letracker/internal/entity"
+)
+
+// ErrInvalidStatusGradeMapping 狀態對應表不合理
+var ErrInvalidStatusGradeMapping = errors.New("invalid status grade mapping")
+
+// DefaultStatusGradeMapping 預設值：打錯字的 Compile Error 不算數，TLE / MLE 通常思路對了只是不夠快，算 Hard
+func DefaultStatusGradeMapping() entity.StatusGradeMapping {
+	return entity.StatusGradeMapping{
+		Statuses: map[string]int{
+			"Accepted":              2,
+			"Wrong Answer":          0,
+			"Runtime Error":         0,
+			"Output Limit Exceeded": 0,
+			"Time Limit Exceeded":   1,
+			"Memory Limit Exceeded": 1,
+			"Compile Error":         entity.GradeIgnore,
+			"Internal Error":        entity.GradeIgnore,
+		},
+	}
+}
+
+// gradeForStatus 查出這個狀態的評分，不在表裡的視為 Again
+func gradeForStatus(mapping entity.StatusGradeMapping, status string) int {
+	if grade, ok := mapping.Statuses[status]; ok {
+		return grade
+	}
+	return 0
+}
+
+// GetStatusGradeMapping 回傳預設值疊加使用者的覆寫
+func (s *reviewServiceImpl) GetStatusGradeMapping(ctx context.Context, userID string) (entity.StatusGradeMapping, error) {
+	mapping := DefaultStatusGradeMapping()
+
+	overrides, err := s.repo.GetStatusGradeMapping(ctx, userID)
+	if err != nil {
+		return entity.StatusGradeMapping{}, err
+	}
+	if overrides != nil {
+		maps.Copy(mapping.Statuses, overrides.Statuses)
+	}
+	return mapping, nil
+}
+
+// SetStatusGradeMapping 儲存使用者的覆寫 (只需要給想改的狀態)，回傳套用後的完整對應表
+func (s *reviewServiceImpl) SetStatusGradeMapping(ctx context.Context, userID string, overrides entity.StatusGradeMapping) (entity.StatusGradeMapping, error) {
+	if err := validateStatusGradeMapping(overrides); err != nil {
+		return entity.StatusGradeMapping{}, err
+	}
+
+	overrides.UserID = userID
+	if err := s.repo.SaveStatusGradeMapping(ctx, overrides); err != nil {
+		return entity.StatusGradeMapping{}, err
+	}
+	return s.GetStatusGradeMapping(ctx, userID)
+}
+
+func validateStatusGradeMapping(mapping entity.StatusGradeMapping) error {
+	for status, grade := range mapping.Statuses {
+		if status == "" {
+			return fmt.Errorf("%w: status must not be empty", ErrInvalidStatusGradeMapping)
+		}
+		if grade < entity.GradeIgnore || grade > 3 {
+			return fmt.Errorf("%w: %s grade must be between 0 and 3, or -1 to ignore", ErrInvalidStatusGradeMapping, status)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"letracker/internal/repository"
+)
+
+const workloadDayLayout = "2006-01-02"
+
+// workloadOracle 實作 srs.WorkloadOracle
+// 以「月」為單位向 DB 查詢每天的複習量並快取，避免 Engine 每算一次就打一次 DB
+// srs.WorkloadOracle 無法回傳錯誤，查詢失敗時記在 loadErr，呼叫端排程完要檢查 err()
+type workloadOracle struct {
+	ctx    context.Context
+	repo   repository.Repository
+	userID string
+
+	counts map[string]int  // Key: "2006-01-02"
+	loaded map[string]bool // Key: "2006-01"
+
+	loadErr error
+}
+
+func newWorkloadOracle(ctx context.Context, repo repository.Repository, userID string) *workloadOracle {
+	return &workloadOracle{
+		ctx:    ctx,
+		repo:   repo,
+		userID: userID,
+		counts: make(map[string]int),
+		loaded: make(map[string]bool),
+	}
+}
+
+// ReviewsOn 回傳某一天已排定的複習數
+func (w *workloadOracle) ReviewsOn(day time.Time) int {
+	w.ensureLoaded(day)
+	return w.counts[day.UTC().Format(workloadDayLayout)]
+}
+
+// add 記錄一筆新排定的複習 (寫入 DB 前先更新快取，讓同一批匯入的題目互相錯開)
+func (w *workloadOracle) add(day time.Time) {
+	w.ensureLoaded(day)
+	w.counts[day.UTC().Format(workloadDayLayout)]++
+}
+
+// remove 把要重新排程的題目從它原本的到期日移除，題目不會跟自己搶位置
+// day 為零值 (還沒排程過) 時不做任何事
+func (w *workloadOracle) remove(day time.Time) {
+	if day.IsZero() {
+		return
+	}
+	w.ensureLoaded(day)
+	key := day.UTC().Format(workloadDayLayout)
+	if w.counts[key] > 0 {
+		w.counts[key]--
+	}
+}
+
+// err 回傳查詢負載時的錯誤 (查詢失敗之後的排程不可信，呼叫端應該放棄寫入)
+func (w *workloadOracle) err() error {
+	return w.loadErr
+}
+
+func (w *workloadOracle) ensureLoaded(day time.Time) {
+	day = day.UTC()
+	month := day.Format("2006-01")
+	if w.loaded[month] || w.loadErr != nil {
+		return
+	}
+
+	from := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	counts, err := w.repo.CountReviewsByDay(w.ctx, w.userID, from, to)
+	if err != nil {
+		w.loadErr = fmt.Errorf("count reviews for %s: %w", month, err)
+		return
+	}
+	w.loaded[month] = true
+	for k, v := range counts {
+		w.counts[k] += v
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWorkloadOracle(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 15, 0, 0, 0, time.UTC) }
+	repo := &fakeRepo{reviewsByDay: map[string]int{"2026-01-10": 3, "2026-01-20": 1, "2026-02-01": 2}}
+	w := newWorkloadOracle(context.Background(), repo, "u")
+
+	if got := w.ReviewsOn(day(1, 10)); got != 3 {
+		t.Errorf("ReviewsOn(01-10) = %d, want 3", got)
+	}
+	if got := w.ReviewsOn(day(1, 11)); got != 0 {
+		t.Errorf("ReviewsOn(01-11) = %d, want 0", got)
+	}
+	if repo.countCalls != 1 {
+		t.Errorf("loaded %d times, want one query per month", repo.countCalls)
+	}
+
+	// 新排定的複習計入，重新排程的題目從原本的那天移除
+	w.add(day(1, 11))
+	w.remove(day(1, 10))
+	w.remove(day(1, 12)) // 沒有排複習的日子不會變成負數
+	w.remove(time.Time{})
+	if got := w.ReviewsOn(day(1, 11)); got != 1 {
+		t.Errorf("ReviewsOn(01-11) after add = %d, want 1", got)
+	}
+	if got := w.ReviewsOn(day(1, 10)); got != 2 {
+		t.Errorf("ReviewsOn(01-10) after remove = %d, want 2", got)
+	}
+	if got := w.ReviewsOn(day(1, 12)); got != 0 {
+		t.Errorf("ReviewsOn(01-12) after remove = %d, want 0", got)
+	}
+
+	if got := w.ReviewsOn(day(2, 1)); got != 2 {
+		t.Errorf("ReviewsOn(02-01) = %d, want 2", got)
+	}
+	if repo.countCalls != 2 {
+		t.Errorf("loaded %d times, want 2 after reading a second month", repo.countCalls)
+	}
+	if err := w.err(); err != nil {
+		t.Errorf("err() = %v, want nil", err)
+	}
+}
+
+func TestWorkloadOracleError(t *testing.T) {
+	queryErr := errors.New("connection reset")
+	w := newWorkloadOracle(context.Background(), &fakeRepo{countErr: queryErr}, "u")
+
+	if got := w.ReviewsOn(time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)); got != 0 {
+		t.Errorf("ReviewsOn() = %d, want 0", got)
+	}
+	if err := w.err(); !errors.Is(err, queryErr) {
+		t.Errorf("err() = %v, want it to wrap the query error", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package optimizer 依照使用者的歷史練習紀錄，擬合改良版 SM-2 的參數
+//
+// 作法：
+//  1. 用候選參數回放每一題的歷史 (跟 service 的 replay 同樣的規則)
+//  2. 每次複習前，用「距離上次的實際天數 / 上次排定的間隔」預測記住的機率
+//  3. 跟實際結果 (Grade > 0 視為記得) 比較，計算 log-loss
+//  4. 在參數範圍內做 pattern search，找出 log-loss 最小的參數
+package optimizer
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"letracker/pkg/srs"
+)
+
+// ErrNotEnoughData 可用來評估的複習次數太少，擬合結果不可信
+var ErrNotEnoughData = errors.New("not enough review history to fit parameters")
+
+// targetRetention 排程的前提：在排定的那一天，記住的機率是 90%
+const targetRetention = 0.9
+
+// sameSessionDays 與 service 的回放邏輯一致：間隔 < 12 小時視為同一次練習
+const sameSessionDays = 0.5
+
+// Review 一次練習紀錄 (對應 study_logs 的一列)
+type Review struct {
+	Date  time.Time
+	Grade int // 0: Again, 1: Hard, 2: Good, 3: Easy
+}
+
+// Options 擬合設定
+type Options struct {
+	MinReviews    int     // 至少要有幾次可評估的複習
+	MaxIterations int     // pattern search 最多幾輪
+	Tolerance     float64 // 步長小於此值就停止
+}
+
+// DefaultOptions 預設擬合設定
+func DefaultOptions() Options {
+	return Options{
+		MinReviews:    20,
+		MaxIterations: 200,
+		Tolerance:     0.001,
+	}
+}
+
+// Result 擬合結果
+type Result struct {
+	Params          srs.LeTrackerParams
+	LogLoss         float64 // 擬合後的平均 log-loss
+	BaselineLogLoss float64 // 預設參數的平均 log-loss (用來比較有沒有變好)
+	ReviewCount     int     // 參與評估的複習次數
+}
+
+// bound 單一參數的合理範圍
+type bound struct{ min, max float64 }
+
+var (
+	hardModifierBound   = bound{0.5, 1.0}
+	easyBonusBound      = bound{1.0, 1.6}
+	retentionBonusBound = bound{1.0, 2.5}
+)
+
+// Fit 以 log-loss 最小化擬合參數
+// histories 的每個元素是一題的全部練習紀錄
+func Fit(histories [][]Review, opts Options) (Result, error) {
+	histories = sortHistories(histories)
+
+	baseline := srs.DefaultLeTrackerParams()
+	baselineLoss, count := Evaluate(histories, baseline)
+	if count < opts.MinReviews {
+		return Result{}, ErrNotEnoughData
+	}
+
+	// Pattern search (Hooke-Jeeves 簡化版)：逐一調整每個參數，沒有進步就縮小步長
+	x := []float64{baseline.HardModifier, baseline.EasyBonus, baseline.RetentionBonus}
+	bounds := []bound{hardModifierBound, easyBonusBound, retentionBonusBound}
+	best := baselineLoss
+	step := 0.1
+
+	for i := 0; i < opts.MaxIterations && step >= opts.Tolerance; i++ {
+		improved := false
+		for d := range x {
+			for _, dir := range []float64{1, -1} {
+				candidate := append([]float64(nil), x...)
+				candidate[d] = clamp(candidate[d]+dir*step, bounds[d])
+				if candidate[d] == x[d] {
+					continue
+				}
+				loss, _ := Evaluate(histories, toParams(candidate))
+				if loss < best {
+					best = loss
+					x = candidate
+					improved = true
+					break
+				}
+			}
+		}
+		if !improved {
+			step /= 2
+		}
+	}
+
+	return Result{
+		Params:          toParams(x),
+		LogLoss:         best,
+		BaselineLogLoss: baselineLoss,
+		ReviewCount:     count,
+	}, nil
+}
+
+// Evaluate 用指定參數回放所有歷史，回傳平均 log-loss 與評估的複習次數
+// histories 必須已按時間排序
+func Evaluate(histories [][]Review, params srs.LeTrackerParams) (float64, int) {
+	// 直接使用 Scheduler (不經過 Engine)，沒有 Fuzz，結果可重現
+	scheduler := srs.LeTrackerSM2{Params: params}
+
+	totalLoss := 0.0
+	count := 0
+
+	for _, reviews := range histories {
+		interval := 0
+		ef := 2.5
+		streak := 0
+		var last time.Time
+
+		for i, review := range reviews {
+			actualDays := 0.0
+			if i > 0 {
+				actualDays = review.Date.Sub(last).Hours() / 24.0
+				// 同一次練習的重複提交不列入計算
+				if actualDays < sameSessionDays {
+					continue
+				}
+			}
+
+			// 有上一次的排程才能預測
+			if i > 0 && interval > 0 {
+				p := predictRecall(actualDays, float64(interval))
+				totalLoss += logLoss(p, review.Grade > 0)
+				count++
+			}
+
+			out := scheduler.Schedule(srs.ReviewInput{
+				CurrentInterval: interval,
+				CurrentEF:       ef,
+				Repetitions:     streak,
+				Grade:           review.Grade,
+				ActualDays:      actualDays,
+				ReviewedAt:      review.Date,
+			})
+			interval = out.Interval
+			ef = out.EaseFactor
+			streak = out.Repetitions
+			last = review.Date
+		}
+	}
+
+	if count == 0 {
+		return 0, 0
+	}
+	return totalLoss / float64(count), count
+}
+
+// predictRecall 指數遺忘曲線：在排定的間隔當天剛好是 targetRetention
+func predictRecall(elapsedDays, intervalDays float64) float64 {
+	return math.Pow(targetRetention, elapsedDays/intervalDays)
+}
+
+func logLoss(p float64, recalled bool) float64 {
+	p = math.Min(math.Max(p, 1e-4), 1-1e-4)
+	if recalled {
+		return -math.Log(p)
+	}
+	return -math.Log(1 - p)
+}
+
+func toParams(x []float64) srs.LeTrackerParams {
+	return srs.LeTrackerParams{
+		HardModifier:   x[0],
+		EasyBonus:      x[1],
+		RetentionBonus: x[2],
+	}
+}
+
+func clamp(v float64, b bound) float64 {
+	return math.Min(math.Max(v, b.min), b.max)
+}
+
+// sortHistories 確保每一題的紀錄都從舊到新
+func sortHistories(histories [][]Review) [][]Review {
+	sorted := make([][]Review, 0, len(histories))
+	for _, reviews := range histories {
+		rs := append([]Review(nil), reviews...)
+		sort.SliceStable(rs, func(i, j int) bool {
+			return rs[i].Date.Before(rs[j].Date)
+		})
+		sorted = append(sorted, rs)
+	}
+	return sorted
+}
@@ -0,0 +1,97 @@
+package optimizer
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"letracker/pkg/srs"
+)
+
+var day0 = time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// lateRecallHistories 每一題都在排定間隔的好幾倍之後才複習，而且每次都記得
+// 預設參數會低估記住的機率，擬合應該把間隔拉長
+func lateRecallHistories(questions int) [][]Review {
+	histories := make([][]Review, 0, questions)
+	for q := 0; q < questions; q++ {
+		var reviews []Review
+		at := day0.AddDate(0, 0, q)
+		for _, gap := range []int{0, 4, 20, 60, 150} {
+			at = at.AddDate(0, 0, gap)
+			reviews = append(reviews, Review{Date: at, Grade: 2})
+		}
+		histories = append(histories, reviews)
+	}
+	return histories
+}
+
+func TestFitImprovesLogLoss(t *testing.T) {
+	result, err := Fit(lateRecallHistories(10), DefaultOptions())
+	if err != nil {
+		t.Fatalf("Fit() error = %v", err)
+	}
+	if result.ReviewCount != 40 {
+		t.Errorf("ReviewCount = %d, want 40", result.ReviewCount)
+	}
+	if !(result.LogLoss < result.BaselineLogLoss) {
+		t.Errorf("LogLoss = %v, want below the baseline %v", result.LogLoss, result.BaselineLogLoss)
+	}
+	if result.Params.RetentionBonus < retentionBonusBound.min || result.Params.RetentionBonus > retentionBonusBound.max ||
+		result.Params.HardModifier < hardModifierBound.min || result.Params.HardModifier > hardModifierBound.max ||
+		result.Params.EasyBonus < easyBonusBound.min || result.Params.EasyBonus > easyBonusBound.max {
+		t.Errorf("Params = %+v, out of bounds", result.Params)
+	}
+
+	// 擬合出來的參數重新評估，要跟回報的 log-loss 一致
+	loss, count := Evaluate(sortHistories(lateRecallHistories(10)), result.Params)
+	if math.Abs(loss-result.LogLoss) > 1e-12 || count != result.ReviewCount {
+		t.Errorf("Evaluate(fitted) = %v, %d, want %v, %d", loss, count, result.LogLoss, result.ReviewCount)
+	}
+}
+
+func TestFitNotEnoughData(t *testing.T) {
+	_, err := Fit(lateRecallHistories(2), DefaultOptions())
+	if !errors.Is(err, ErrNotEnoughData) {
+		t.Errorf("Fit() error = %v, want ErrNotEnoughData", err)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	at := func(days float64) time.Time { return day0.Add(time.Duration(days * 24 * float64(time.Hour))) }
+
+	tests := []struct {
+		name      string
+		reviews   []Review
+		wantCount int
+	}{
+		{name: "first review is not predicted", reviews: []Review{{Date: at(0), Grade: 2}}, wantCount: 0},
+		{name: "every later review is predicted", reviews: []Review{{Date: at(0), Grade: 2}, {Date: at(1), Grade: 2}, {Date: at(7), Grade: 0}}, wantCount: 2},
+		{name: "same-session resubmissions are skipped", reviews: []Review{{Date: at(0), Grade: 0}, {Date: at(0.1), Grade: 2}, {Date: at(1), Grade: 2}}, wantCount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loss, count := Evaluate([][]Review{tt.reviews}, srs.DefaultLeTrackerParams())
+			if count != tt.wantCount {
+				t.Errorf("count = %d, want %d", count, tt.wantCount)
+			}
+			if count == 0 && loss != 0 {
+				t.Errorf("loss = %v, want 0 with nothing to evaluate", loss)
+			}
+			if count > 0 && loss <= 0 {
+				t.Errorf("loss = %v, want positive", loss)
+			}
+		})
+	}
+}
+
+func TestPredictRecall(t *testing.T) {
+	if p := predictRecall(10, 10); math.Abs(p-targetRetention) > 1e-12 {
+		t.Errorf("predictRecall on the due date = %v, want %v", p, targetRetention)
+	}
+	if predictRecall(20, 10) >= predictRecall(5, 10) {
+		t.Error("recall should drop the later the review")
+	}
+}
@@ -0,0 +1,51 @@
+package srs
+
+// SchedulerConfig 使用者可調整的排程設定 (原本寫死在演算法裡的常數)
+// 欄位為零值時使用預設值
+type SchedulerConfig struct {
+	MaximumInterval int     // 間隔上限 (天)，所有演算法都適用
+	MinimumEF       float64 // EF 底限 (預設 1.3)，SM-2 系列適用
+
+	// Hard / Easy 的間隔倍率，只影響改良版 SM-2
+	// 零值代表「自動」：有 optimizer 擬合的參數就用擬合值，否則用預設值 (0.8 / 1.1)
+	HardModifier float64
+	EasyBonus    float64
+
+	// 第二次複習的間隔 (天)，依序為 Hard / Good / Easy (預設 3 / 5 / 7)
+	SecondIntervals [3]int
+
+	FuzzThresholdDays int     // 間隔超過此天數才加入 Fuzz (預設 10)
+	FuzzRange         float64 // Fuzz 的波動範圍 (預設 0.05，即 ±5%)
+}
+
+// DefaultSchedulerConfig 回傳預設設定 (倍率為「自動」)
+func DefaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		MaximumInterval:   36500,
+		MinimumEF:         1.3,
+		SecondIntervals:   [3]int{3, 5, 7},
+		FuzzThresholdDays: 10,
+		FuzzRange:         0.05,
+	}
+}
+
+// WithDefaults 把零值的欄位補成預設值
+func (c SchedulerConfig) WithDefaults() SchedulerConfig {
+	def := DefaultSchedulerConfig()
+	if c.MaximumInterval <= 0 {
+		c.MaximumInterval = def.MaximumInterval
+	}
+	if c.MinimumEF <= 0 {
+		c.MinimumEF = def.MinimumEF
+	}
+	if c.SecondIntervals == ([3]int{}) {
+		c.SecondIntervals = def.SecondIntervals
+	}
+	if c.FuzzThresholdDays <= 0 {
+		c.FuzzThresholdDays = def.FuzzThresholdDays
+	}
+	if c.FuzzRange <= 0 {
+		c.FuzzRange = def.FuzzRange
+	}
+	return c
+}
@@ -0,0 +1,32 @@
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+// FinalWeek 面試前的最後衝刺期，範圍內的每一題都要在這段期間至少複習一次
+const FinalWeek = 7 * 24 * time.Hour
+
+// CapToDeadline 面試模式：間隔如果會跳過最後一週 (直接排到面試之後)，
+// 就把下次複習提前到最後一週的中間，保留答錯之後再補救的時間
+// 已經在最後一週內練習過的題目不再壓縮，面試之後的排程照常
+//
+// 回傳的是「幾天後複習」，只影響 NextReviewAt；演算法的間隔 (Interval) 照存，
+// 下次複習時依實際經過的天數給部分分數，不會因為提前一次就失去累積的間隔
+func CapToDeadline(reviewedAt, deadline time.Time, interval int) int {
+	if deadline.IsZero() || !reviewedAt.Before(deadline.Add(-FinalWeek)) {
+		return interval
+	}
+	if reviewedAt.AddDate(0, 0, interval).Before(deadline) {
+		return interval
+	}
+
+	// 無條件捨去：寧可早半天，也不要晚於最後一週的中間 (面試前 3.5 天)
+	target := deadline.Add(-FinalWeek / 2)
+	days := int(math.Floor(target.Sub(reviewedAt).Hours() / 24))
+	if days < 1 {
+		days = 1
+	}
+	return min(days, interval)
+}
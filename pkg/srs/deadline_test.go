@@ -0,0 +1,57 @@
+package srs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCapToDeadline(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name       string
+		reviewedAt time.Time
+		deadline   time.Time
+		interval   int
+		want       int
+	}{
+		{name: "no interview", reviewedAt: day(1), interval: 40, want: 40},
+		{name: "due before the interview", reviewedAt: day(1), deadline: day(31), interval: 20, want: 20},
+		{name: "due inside the final week", reviewedAt: day(1), deadline: day(31), interval: 28, want: 28},
+		{name: "due on the interview day", reviewedAt: day(1), deadline: day(31), interval: 30, want: 26},
+		{name: "skips the interview", reviewedAt: day(1), deadline: day(31), interval: 60, want: 26},
+		{name: "rounds down to stay before the middle of the final week", reviewedAt: day(20), deadline: day(31), interval: 15, want: 7},
+		{name: "already inside the final week", reviewedAt: day(25), deadline: day(31), interval: 15, want: 15},
+		{name: "after the interview", reviewedAt: day(31), deadline: day(20), interval: 15, want: 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CapToDeadline(tt.reviewedAt, tt.deadline, tt.interval); got != tt.want {
+				t.Errorf("CapToDeadline() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineReviewDeadlineKeepsInterval(t *testing.T) {
+	reviewedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	engine := NewEngine(SM2{}, WithClock(FixedClock(reviewedAt)))
+
+	// SM-2 給 15 天，會跳過 1/14 面試前的最後一週：提前到最後一週的中間，間隔照存
+	out := engine.Review(FuzzKey{}, ReviewInput{
+		Phase:           PhaseReview,
+		Repetitions:     2,
+		CurrentInterval: 6,
+		CurrentEF:       2.5,
+		Grade:           2,
+		ReviewedAt:      reviewedAt,
+		Deadline:        time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC),
+	})
+	if out.Interval != 15 {
+		t.Errorf("Interval = %d, want 15", out.Interval)
+	}
+	if want := reviewedAt.AddDate(0, 0, 9); !out.NextReviewAt.Equal(want) {
+		t.Errorf("NextReviewAt = %v, want %v", out.NextReviewAt, want)
+	}
+}
@@ -0,0 +1,239 @@
+package srs
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Clock 提供「現在時間」，測試與回放可以注入固定的時鐘
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock 使用系統時間
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// FixedClock 永遠回傳同一個時間
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time { return time.Time(c) }
+
+// FuzzKey 用來產生 Fuzz 的識別資料：同一位使用者、同一題、第幾次複習
+type FuzzKey struct {
+	UserID      string
+	QuestionID  string
+	ReviewCount int
+}
+
+// FuzzSource 回傳 [0, 1) 之間的值，決定 Fuzz 的方向與大小
+type FuzzSource interface {
+	Float64(key FuzzKey) float64
+}
+
+// HashFuzz 以 FuzzKey 的穩定雜湊產生 Fuzz
+// 同樣的 (user, question, review count) 永遠得到同樣的值，重跑匯入會得到完全相同的排程
+type HashFuzz struct{}
+
+func (HashFuzz) Float64(key FuzzKey) float64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%s|%d", key.UserID, key.QuestionID, key.ReviewCount)
+	// 取高 53 bits 轉成 [0, 1) 的浮點數
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
+
+// WorkloadOracle 查詢某一天已經排了幾題複習
+// 由 Service 實作 (查 user_question_stats.next_review_at)，Engine 用來做負載平衡
+type WorkloadOracle interface {
+	ReviewsOn(day time.Time) int
+}
+
+// RandomFuzz 使用全域 math/rand (舊行為，不可重現)
+type RandomFuzz struct{}
+
+func (RandomFuzz) Float64(FuzzKey) float64 { return rand.Float64() }
+
+// Engine 包裝 Scheduler，負責所有跟「時間」與「隨機」有關的部分：
+// - 以 Clock (或 ReviewInput.ReviewedAt) 決定 NextReviewAt 的錨點
+// - 以 FuzzSource 對長間隔加入 ±5% (可由 SchedulerConfig 調整) 的波動，防止題目堆積在同一天 (限 Fuzzable 的演算法)
+// - 有 WorkloadOracle 時，在 Fuzz 的範圍內挑選複習量最少的那一天
+// - 有 LearningSteps 時，新題與答錯的題目先走分鐘級的學習步驟
+type Engine struct {
+	scheduler Scheduler
+	clock     Clock
+	fuzz      FuzzSource
+	workload  WorkloadOracle
+	steps     LearningSteps
+	config    SchedulerConfig
+}
+
+// EngineOption 設定 Engine 的選項
+type EngineOption func(*Engine)
+
+// WithClock 注入時鐘 (預設 SystemClock)
+func WithClock(clock Clock) EngineOption {
+	return func(e *Engine) { e.clock = clock }
+}
+
+// WithFuzz 注入 Fuzz 來源 (預設 HashFuzz)
+func WithFuzz(fuzz FuzzSource) EngineOption {
+	return func(e *Engine) { e.fuzz = fuzz }
+}
+
+// WithWorkload 注入負載查詢，啟用負載平衡的 Fuzz (預設關閉)
+func WithWorkload(workload WorkloadOracle) EngineOption {
+	return func(e *Engine) { e.workload = workload }
+}
+
+// WithLearningSteps 啟用學習步驟 (預設關閉：新題直接 1 天、Again 直接 +1 天)
+func WithLearningSteps(steps LearningSteps) EngineOption {
+	return func(e *Engine) { e.steps = steps }
+}
+
+// WithConfig 套用使用者的排程設定 (間隔上限、Fuzz 門檻與範圍)
+func WithConfig(config SchedulerConfig) EngineOption {
+	return func(e *Engine) { e.config = config }
+}
+
+// NewEngine 建立 Engine，預設使用系統時鐘與可重現的 HashFuzz
+func NewEngine(scheduler Scheduler, opts ...EngineOption) *Engine {
+	e := &Engine{
+		scheduler: scheduler,
+		clock:     SystemClock{},
+		fuzz:      HashFuzz{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.config = e.config.WithDefaults()
+	return e
+}
+
+// Scheduler 回傳 Engine 使用的演算法
+func (e *Engine) Scheduler() Scheduler { return e.scheduler }
+
+// Now 回傳 Engine 時鐘的現在時間
+func (e *Engine) Now() time.Time { return e.clock.Now() }
+
+// Review 計算下一次複習
+// key 用來產生可重現的 Fuzz；input.ReviewedAt 為零值時以 Clock 的現在時間為準
+func (e *Engine) Review(key FuzzKey, input ReviewInput) ReviewOutput {
+	if input.ReviewedAt.IsZero() {
+		input.ReviewedAt = e.clock.Now()
+	}
+
+	switch {
+	case (input.Phase == PhaseNew || input.Phase == PhaseLearning) && len(e.steps.Learning) > 0:
+		return e.reviewLearning(key, input)
+	case input.Phase == PhaseRelearning && len(e.steps.Relearning) > 0:
+		return e.reviewRelearning(input)
+	}
+
+	out := e.schedule(key, input)
+	// 只有畢業過 (答錯前 Repetitions > 0) 的題目答錯才算 Lapse
+	// 從沒學會的題目 (例如回放時一直 WA) 答錯只是還在學，不會累積成 leech
+	out.Lapsed = input.Grade == 0 && input.Phase == PhaseReview && input.Repetitions > 0
+
+	switch {
+	case out.Lapsed && len(e.steps.Relearning) > 0:
+		// 複習中答錯 (Lapse)：演算法已經處理 EF/間隔的懲罰，接著進入重新學習步驟
+		return stepOutput(input, out, PhaseRelearning, 0, e.steps.Relearning[0])
+	case input.Grade == 0 && !out.Lapsed && len(e.steps.Learning) > 0:
+		// 還沒畢業的題目答錯：回到新題的學習步驟
+		return stepOutput(input, out, PhaseLearning, 0, e.steps.Learning[0])
+	}
+	return out
+}
+
+// schedule 呼叫演算法，並處理 Fuzz 與 NextReviewAt 的錨點
+func (e *Engine) schedule(key FuzzKey, input ReviewInput) ReviewOutput {
+	out := e.scheduler.Schedule(input)
+	out.Phase = PhaseReview
+	out.Step = 0
+
+	trace := &tracer{enabled: input.Explain, steps: out.Trace}
+	trace.add(TraceStep{Step: TraceScheduler, Output: float64(out.Interval), Note: e.scheduler.Name()})
+
+	// ---------------------------------------------------------
+	// Fuzzing (模糊化) - 防止題目堆積
+	// ---------------------------------------------------------
+	// 當間隔大於門檻 (預設 10 天) 時，加入 ±FuzzRange (預設 5%) 的波動
+	// 只有實作 Fuzzable 的演算法才會被打散
+	if e.fuzzable() && out.Interval > e.config.FuzzThresholdDays {
+		fuzzFactor := 1 - e.config.FuzzRange + e.fuzz.Float64(key)*2*e.config.FuzzRange
+		fuzzed := int(math.Round(float64(out.Interval) * fuzzFactor))
+		trace.add(TraceStep{Step: TraceFuzz, Input: float64(out.Interval), Factor: fuzzFactor, Output: float64(fuzzed)})
+
+		// 回放時中途的排程落在過去，不需要 (也無法) 平衡
+		if e.workload != nil && input.ReviewedAt.AddDate(0, 0, out.Interval).After(e.clock.Now()) {
+			balanced := e.balance(input.ReviewedAt, out.Interval, fuzzed)
+			trace.add(TraceStep{Step: TraceBalance, Input: float64(fuzzed), Output: float64(balanced),
+				Note: fmt.Sprintf("%d reviews already due that day", e.workload.ReviewsOn(input.ReviewedAt.AddDate(0, 0, balanced)))})
+			fuzzed = balanced
+		}
+		out.Interval = fuzzed
+	}
+
+	if out.Interval > e.config.MaximumInterval {
+		trace.add(TraceStep{Step: TraceMaximumInterval, Input: float64(out.Interval), Output: float64(e.config.MaximumInterval)})
+		out.Interval = e.config.MaximumInterval
+	}
+
+	// 面試模式：壓縮在 Fuzz 之後，避免 Fuzz 又把題目推到面試之後
+	// 只提前 NextReviewAt，out.Interval 維持演算法的間隔
+	next := out.Interval
+	if capped := CapToDeadline(input.ReviewedAt, input.Deadline, out.Interval); capped != out.Interval {
+		trace.add(TraceStep{Step: TraceDeadline, Input: float64(out.Interval), Output: float64(capped),
+			Note: "interview on " + input.Deadline.Format("2006-01-02")})
+		next = capped
+	}
+
+	out.NextReviewAt = input.ReviewedAt.AddDate(0, 0, next)
+	out.IntervalMinutes = next * minutesPerDay
+	out.Trace = trace.steps
+	return out
+}
+
+// fuzzable 演算法是否要加入 Fuzz 與負載平衡
+func (e *Engine) fuzzable() bool {
+	f, ok := e.scheduler.(Fuzzable)
+	return ok && f.Fuzzable()
+}
+
+// balance 在 Fuzz 的範圍 (預設 [interval*0.95, interval*1.05]) 之間挑選複習量最少的一天
+// 同樣少的話，選最接近原本 Fuzz 結果的那天 (維持可重現性)
+func (e *Engine) balance(reviewedAt time.Time, interval, fuzzed int) int {
+	minDays := int(math.Round(float64(interval) * (1 - e.config.FuzzRange)))
+	maxDays := int(math.Round(float64(interval) * (1 + e.config.FuzzRange)))
+
+	best := fuzzed
+	bestLoad := -1
+	for days := minDays; days <= maxDays; days++ {
+		load := e.workload.ReviewsOn(reviewedAt.AddDate(0, 0, days))
+		if bestLoad < 0 || load < bestLoad ||
+			(load == bestLoad && absInt(days-fuzzed) < absInt(best-fuzzed)) {
+			best = days
+			bestLoad = load
+		}
+	}
+	return best
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// reviewTime 取得本次練習的時間 (Scheduler 單獨使用時沒有 Clock，退回系統時間)
+func reviewTime(input ReviewInput) time.Time {
+	if input.ReviewedAt.IsZero() {
+		return time.Now()
+	}
+	return input.ReviewedAt
+}
@@ -0,0 +1,188 @@
+package srs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// constFuzz 固定回傳同一個值的 FuzzSource
+type constFuzz float64
+
+func (f constFuzz) Float64(FuzzKey) float64 { return float64(f) }
+
+func TestHashFuzz(t *testing.T) {
+	key := FuzzKey{UserID: "u", QuestionID: "q", ReviewCount: 3}
+
+	first := HashFuzz{}.Float64(key)
+	if first < 0 || first >= 1 {
+		t.Fatalf("Float64() = %v, want [0, 1)", first)
+	}
+	for i := 0; i < 10; i++ {
+		if got := (HashFuzz{}).Float64(key); got != first {
+			t.Fatalf("Float64() = %v on run %d, want %v every time", got, i, first)
+		}
+	}
+
+	others := []FuzzKey{
+		{UserID: "u2", QuestionID: "q", ReviewCount: 3},
+		{UserID: "u", QuestionID: "q2", ReviewCount: 3},
+		{UserID: "u", QuestionID: "q", ReviewCount: 4},
+	}
+	for _, other := range others {
+		if (HashFuzz{}).Float64(other) == first {
+			t.Errorf("Float64(%+v) = Float64(%+v), want different keys to fuzz differently", other, key)
+		}
+	}
+}
+
+func TestEngineReviewUsesClock(t *testing.T) {
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	engine := NewEngine(SM2{}, WithClock(FixedClock(now)))
+
+	out := engine.Review(FuzzKey{}, ReviewInput{Grade: 2, CurrentEF: 2.5})
+	if want := now.AddDate(0, 0, 1); !out.NextReviewAt.Equal(want) {
+		t.Errorf("NextReviewAt = %v, want %v", out.NextReviewAt, want)
+	}
+}
+
+func TestEngineFuzz(t *testing.T) {
+	reviewedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 改良版 SM-2 第 3 次複習之後的間隔 (超過 Fuzz 門檻)
+	input := ReviewInput{Grade: 2, CurrentInterval: 40, CurrentEF: 2.5, Repetitions: 4, ReviewedAt: reviewedAt}
+	unfuzzed := LeTrackerSM2{}.Schedule(input).Interval
+
+	tests := []struct {
+		name      string
+		scheduler Scheduler
+		fuzz      float64
+		want      int
+	}{
+		{name: "lowest fuzz", scheduler: LeTrackerSM2{}, fuzz: 0, want: int(float64(unfuzzed)*0.95 + 0.5)},
+		{name: "middle fuzz keeps the interval", scheduler: LeTrackerSM2{}, fuzz: 0.5, want: unfuzzed},
+		{name: "non-fuzzable scheduler is left alone", scheduler: SM2{}, fuzz: 0, want: SM2{}.Schedule(input).Interval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(tt.scheduler, WithClock(FixedClock(reviewedAt)), WithFuzz(constFuzz(tt.fuzz)))
+			out := engine.Review(FuzzKey{}, input)
+			if out.Interval != tt.want {
+				t.Errorf("Interval = %d, want %d", out.Interval, tt.want)
+			}
+			if want := reviewedAt.AddDate(0, 0, tt.want); !out.NextReviewAt.Equal(want) {
+				t.Errorf("NextReviewAt = %v, want %v", out.NextReviewAt, want)
+			}
+		})
+	}
+}
+
+func TestTimelineIsReproducible(t *testing.T) {
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	var events []ReviewEvent
+	at := start
+	for i, gap := range []int{0, 1, 4, 10, 25, 60, 2, 5, 14, 40, 90, 200} {
+		at = at.AddDate(0, 0, gap)
+		events = append(events, ReviewEvent{At: at, Grade: []int{2, 3, 2, 1, 2, 3, 0, 2, 2, 3, 2, 2}[i]})
+	}
+	opts := TimelineOptions{UserID: "u", QuestionID: "q", MinGap: 12 * time.Hour, Explain: true}
+	initial := CardState{EaseFactor: 2.5}
+
+	run := func() []byte {
+		// 每次都建立新的 Engine，時鐘在所有練習之後
+		engine := NewEngine(LeTrackerSM2{}, WithClock(FixedClock(at.AddDate(1, 0, 0))))
+		data, err := json.Marshal(engine.Timeline(initial, events, opts))
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		return data
+	}
+
+	first := run()
+	for i := 0; i < 5; i++ {
+		if got := run(); string(got) != string(first) {
+			t.Fatalf("replay %d differs from the first replay", i)
+		}
+	}
+
+	// 換一題 Fuzz 就不同 (確認真的有 Fuzz，而不是剛好都沒超過門檻)
+	opts.QuestionID = "other"
+	engine := NewEngine(LeTrackerSM2{}, WithClock(FixedClock(at.AddDate(1, 0, 0))))
+	other, _ := json.Marshal(engine.Timeline(initial, events, opts))
+	if string(other) == string(first) {
+		t.Error("replays of different questions should fuzz differently")
+	}
+}
+
+func TestTimelineMinGap(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	events := []ReviewEvent{
+		{At: start, Grade: 0},
+		{At: start.Add(time.Hour), Grade: 2},
+		{At: start.AddDate(0, 0, 1), Grade: 2},
+	}
+	engine := NewEngine(SM2{}, WithClock(FixedClock(start.AddDate(0, 1, 0))))
+
+	snapshots := engine.Timeline(CardState{EaseFactor: 2.5}, events, TimelineOptions{MinGap: 12 * time.Hour})
+	if len(snapshots) != 3 {
+		t.Fatalf("got %d snapshots, want 3", len(snapshots))
+	}
+	if !snapshots[1].Skipped {
+		t.Error("a review within MinGap should be skipped")
+	}
+	if snapshots[1].State != snapshots[0].State {
+		t.Error("a skipped review should keep the previous state")
+	}
+	if snapshots[2].Skipped || snapshots[2].State.ReviewCount != 2 {
+		t.Errorf("third review: Skipped = %v, ReviewCount = %d, want a second scheduled review", snapshots[2].Skipped, snapshots[2].State.ReviewCount)
+	}
+}
+
+// mapWorkload 以日期 ("2006-01-02") 查詢複習量的 WorkloadOracle
+type mapWorkload map[string]int
+
+func (w mapWorkload) ReviewsOn(day time.Time) int { return w[day.Format("2006-01-02")] }
+
+func TestEngineBalance(t *testing.T) {
+	reviewedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	input := ReviewInput{Grade: 2, CurrentInterval: 40, CurrentEF: 2.5, Repetitions: 4, ReviewedAt: reviewedAt}
+	interval := LeTrackerSM2{}.Schedule(input).Interval
+	minDays := int(float64(interval)*0.95 + 0.5)
+	maxDays := int(float64(interval)*1.05 + 0.5)
+
+	// busy 把 [minDays, maxDays] 每天都排滿，只有 light 的那幾天比較空
+	busy := func(light map[int]int) mapWorkload {
+		w := mapWorkload{}
+		for days := minDays; days <= maxDays; days++ {
+			load, ok := light[days]
+			if !ok {
+				load = 5
+			}
+			w[reviewedAt.AddDate(0, 0, days).Format("2006-01-02")] = load
+		}
+		return w
+	}
+
+	tests := []struct {
+		name      string
+		scheduler Scheduler
+		workload  mapWorkload
+		now       time.Time
+		want      int
+	}{
+		{name: "least busy day", scheduler: LeTrackerSM2{}, workload: busy(map[int]int{maxDays: 1}), now: reviewedAt, want: maxDays},
+		{name: "ties go to the day closest to the fuzz", scheduler: LeTrackerSM2{}, workload: busy(map[int]int{minDays: 0, interval + 1: 0}), now: reviewedAt, want: interval + 1},
+		{name: "even load keeps the fuzzed day", scheduler: LeTrackerSM2{}, workload: busy(nil), now: reviewedAt, want: interval},
+		{name: "past reviews in a replay are not balanced", scheduler: LeTrackerSM2{}, workload: busy(map[int]int{maxDays: 0}), now: reviewedAt.AddDate(1, 0, 0), want: interval},
+		{name: "non-fuzzable scheduler is not balanced", scheduler: SM2{}, workload: busy(map[int]int{minDays: 0}), now: reviewedAt, want: SM2{}.Schedule(input).Interval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(tt.scheduler, WithClock(FixedClock(tt.now)), WithFuzz(constFuzz(0.5)), WithWorkload(tt.workload))
+			if out := engine.Review(FuzzKey{}, input); out.Interval != tt.want {
+				t.Errorf("Interval = %d, want %d", out.Interval, tt.want)
+			}
+		})
+	}
+}
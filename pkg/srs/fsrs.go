@@ -0,0 +1,162 @@
+package srs
+
+import "math"
+
+// FSRS 遺忘曲線常數 (FSRS-4.5)
+// R(t, S) = (1 + factor * t / S) ^ decay，t = S 時 R = 90%
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+)
+
+// DefaultFSRSWeights FSRS-4.5 官方預設權重 (17 個參數)
+var DefaultFSRSWeights = [17]float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031,
+	1.6474, 0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+// FSRS 是 Free Spaced Repetition Scheduler (v4.5) 的實作
+// 不同於 SM-2 只看 EF / Interval，FSRS 為每一題追蹤兩個記憶狀態：
+// - Stability (S)：記憶穩定度，R 從 100% 掉到 90% 所需的天數
+// - Difficulty (D)：題目難度 1~10，決定 S 成長的速度
+// 間隔由「目標記憶保留率」反推，而不是乘上固定倍數，因此不會有 SM-2 的 ease hell
+type FSRS struct {
+	Weights          [17]float64
+	RequestRetention float64 // 目標記憶保留率 (例如 0.9)
+	MaximumInterval  int     // 最大間隔 (天)
+}
+
+// NewFSRS 使用預設權重與 90% 目標保留率
+func NewFSRS() FSRS {
+	return FSRS{
+		Weights:          DefaultFSRSWeights,
+		RequestRetention: 0.9,
+		MaximumInterval:  36500,
+	}
+}
+
+func (FSRS) Name() string { return AlgorithmFSRS }
+
+// Schedule 計算下一次複習
+// EaseFactor 在 FSRS 中不使用，原值傳回，方便之後切換回 SM-2 系列
+func (f FSRS) Schedule(input ReviewInput) ReviewOutput {
+	rating := float64(clampGrade(input.Grade) + 1) // 轉成 FSRS 的 1(Again) ~ 4(Easy)
+
+	var stability, difficulty float64
+	if input.Stability <= 0 {
+		// 第一次複習 (或是從 SM-2 切換過來還沒有記憶狀態)
+		stability = f.initStability(rating)
+		difficulty = f.initDifficulty(rating)
+	} else {
+		r := forgettingCurve(fsrsElapsedDays(input), input.Stability)
+		if rating == 1 {
+			stability = f.nextForgetStability(input.Difficulty, input.Stability, r)
+		} else {
+			stability = f.nextRecallStability(input.Difficulty, input.Stability, r, rating)
+		}
+		difficulty = f.nextDifficulty(input.Difficulty, rating)
+	}
+
+	interval := f.nextInterval(stability)
+
+	repetitions := input.Repetitions + 1
+	if rating == 1 {
+		repetitions = 0
+	}
+
+	return ReviewOutput{
+		NextReviewAt: reviewTime(input).AddDate(0, 0, interval),
+		Interval:     interval,
+		EaseFactor:   input.CurrentEF,
+		Repetitions:  repetitions,
+		Stability:    stability,
+		Difficulty:   difficulty,
+	}
+}
+
+// fsrsElapsedDays 距離上次複習的天數
+// 回放模式有 ActualDays；即時練習沒有的話，假設使用者是準時複習
+func fsrsElapsedDays(input ReviewInput) float64 {
+	if input.ActualDays > 0 {
+		return input.ActualDays
+	}
+	return float64(input.CurrentInterval)
+}
+
+// forgettingCurve 經過 elapsedDays 天後的記憶保留率
+func forgettingCurve(elapsedDays, stability float64) float64 {
+	return math.Pow(1+fsrsFactor*elapsedDays/stability, fsrsDecay)
+}
+
+// nextInterval 由目標保留率反推間隔：I = S / factor * (R^(1/decay) - 1)
+func (f FSRS) nextInterval(stability float64) int {
+	days := stability / fsrsFactor * (math.Pow(f.RequestRetention, 1/fsrsDecay) - 1)
+	interval := int(math.Round(days))
+	if interval < 1 {
+		interval = 1
+	}
+	if f.MaximumInterval > 0 && interval > f.MaximumInterval {
+		interval = f.MaximumInterval
+	}
+	return interval
+}
+
+// S0(G) = w[G-1]
+func (f FSRS) initStability(rating float64) float64 {
+	return math.Max(f.Weights[int(rating)-1], 0.1)
+}
+
+// D0(G) = w4 - (G-3) * w5
+func (f FSRS) initDifficulty(rating float64) float64 {
+	return clampDifficulty(f.Weights[4] - (rating-3)*f.Weights[5])
+}
+
+// D' = w7 * D0(3) + (1 - w7) * (D - w6 * (G-3))  (mean reversion)
+func (f FSRS) nextDifficulty(d, rating float64) float64 {
+	next := d - f.Weights[6]*(rating-3)
+	return clampDifficulty(f.Weights[7]*f.initDifficulty(3) + (1-f.Weights[7])*next)
+}
+
+// 答對後的新穩定度
+// S' = S * (e^w8 * (11-D) * S^-w9 * (e^(w10*(1-R)) - 1) * hardPenalty * easyBonus + 1)
+func (f FSRS) nextRecallStability(d, s, r, rating float64) float64 {
+	hardPenalty := 1.0
+	if rating == 2 {
+		hardPenalty = f.Weights[15]
+	}
+	easyBonus := 1.0
+	if rating == 4 {
+		easyBonus = f.Weights[16]
+	}
+	return s * (math.Exp(f.Weights[8])*
+		(11-d)*
+		math.Pow(s, -f.Weights[9])*
+		(math.Exp(f.Weights[10]*(1-r))-1)*
+		hardPenalty*
+		easyBonus + 1)
+}
+
+// 答錯後的新穩定度
+// S' = w11 * D^-w12 * ((S+1)^w13 - 1) * e^(w14*(1-R))
+func (f FSRS) nextForgetStability(d, s, r float64) float64 {
+	next := f.Weights[11] *
+		math.Pow(d, -f.Weights[12]) *
+		(math.Pow(s+1, f.Weights[13]) - 1) *
+		math.Exp(f.Weights[14]*(1-r))
+	// 答錯後的穩定度不應該比原本還高
+	return math.Max(0.1, math.Min(next, s))
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Min(math.Max(d, 1), 10)
+}
+
+func clampGrade(grade int) int {
+	if grade < 0 {
+		return 0
+	}
+	if grade > 3 {
+		return 3
+	}
+	return grade
+}
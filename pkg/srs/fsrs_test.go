@@ -0,0 +1,112 @@
+package srs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestForgettingCurve(t *testing.T) {
+	// t = S 時保留率剛好是 90%
+	for _, s := range []float64{0.5, 3.7, 40} {
+		if r := forgettingCurve(s, s); math.Abs(r-0.9) > 1e-9 {
+			t.Errorf("forgettingCurve(%v, %v) = %v, want 0.9", s, s, r)
+		}
+	}
+	if forgettingCurve(20, 10) >= forgettingCurve(5, 10) {
+		t.Error("retention should drop as time passes")
+	}
+}
+
+func TestFSRSFirstReview(t *testing.T) {
+	reviewedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		grade          int
+		wantStability  float64
+		wantDifficulty float64
+		wantInterval   int
+		wantReps       int
+	}{
+		// 目標保留率 90% 時，間隔就等於 Stability
+		{name: "Again", grade: 0, wantStability: 0.4872, wantDifficulty: 7.6214, wantInterval: 1, wantReps: 0},
+		{name: "Hard", grade: 1, wantStability: 1.4003, wantDifficulty: 6.3916, wantInterval: 1, wantReps: 1},
+		{name: "Good", grade: 2, wantStability: 3.7145, wantDifficulty: 5.1618, wantInterval: 4, wantReps: 1},
+		{name: "Easy", grade: 3, wantStability: 13.8206, wantDifficulty: 3.932, wantInterval: 14, wantReps: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := NewFSRS().Schedule(ReviewInput{Grade: tt.grade, CurrentEF: 2.5, ReviewedAt: reviewedAt})
+			if math.Abs(out.Stability-tt.wantStability) > 1e-9 {
+				t.Errorf("Stability = %v, want %v", out.Stability, tt.wantStability)
+			}
+			if math.Abs(out.Difficulty-tt.wantDifficulty) > 1e-9 {
+				t.Errorf("Difficulty = %v, want %v", out.Difficulty, tt.wantDifficulty)
+			}
+			if out.Interval != tt.wantInterval {
+				t.Errorf("Interval = %d, want %d", out.Interval, tt.wantInterval)
+			}
+			if out.Repetitions != tt.wantReps {
+				t.Errorf("Repetitions = %d, want %d", out.Repetitions, tt.wantReps)
+			}
+			if want := reviewedAt.AddDate(0, 0, tt.wantInterval); !out.NextReviewAt.Equal(want) {
+				t.Errorf("NextReviewAt = %v, want %v", out.NextReviewAt, want)
+			}
+			if out.EaseFactor != 2.5 {
+				t.Errorf("EaseFactor = %v, want it passed through", out.EaseFactor)
+			}
+		})
+	}
+}
+
+func TestFSRSLaterReview(t *testing.T) {
+	f := NewFSRS()
+	input := ReviewInput{
+		CurrentInterval: 10,
+		ActualDays:      10,
+		Repetitions:     3,
+		Stability:       10,
+		Difficulty:      5,
+		ReviewedAt:      time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	review := func(grade int) ReviewOutput {
+		in := input
+		in.Grade = grade
+		return f.Schedule(in)
+	}
+
+	again, hard, good, easy := review(0), review(1), review(2), review(3)
+
+	if again.Stability > input.Stability {
+		t.Errorf("Again Stability = %v, should not exceed %v", again.Stability, input.Stability)
+	}
+	if again.Repetitions != 0 {
+		t.Errorf("Again Repetitions = %d, want 0", again.Repetitions)
+	}
+	if again.Difficulty <= input.Difficulty {
+		t.Errorf("Again Difficulty = %v, should increase from %v", again.Difficulty, input.Difficulty)
+	}
+	if !(hard.Stability > input.Stability && hard.Stability < good.Stability && good.Stability < easy.Stability) {
+		t.Errorf("Stability should grow Hard < Good < Easy: %v %v %v", hard.Stability, good.Stability, easy.Stability)
+	}
+	if !(hard.Interval <= good.Interval && good.Interval <= easy.Interval) {
+		t.Errorf("Interval should grow Hard <= Good <= Easy: %d %d %d", hard.Interval, good.Interval, easy.Interval)
+	}
+	if good.Repetitions != 4 {
+		t.Errorf("Good Repetitions = %d, want 4", good.Repetitions)
+	}
+	if easy.Difficulty >= input.Difficulty {
+		t.Errorf("Easy Difficulty = %v, should decrease from %v", easy.Difficulty, input.Difficulty)
+	}
+}
+
+func TestFSRSMaximumInterval(t *testing.T) {
+	f := NewFSRS()
+	f.MaximumInterval = 30
+
+	out := f.Schedule(ReviewInput{Grade: 3, Stability: 200, Difficulty: 3, CurrentInterval: 200, ActualDays: 200})
+	if out.Interval != 30 {
+		t.Errorf("Interval = %d, want 30", out.Interval)
+	}
+}
@@ -0,0 +1,123 @@
+package srs
+
+import (
+	"fmt"
+	"time"
+)
+
+// 題目目前所在的階段
+const (
+	PhaseNew        = ""           // 從來沒練習過
+	PhaseLearning   = "learning"   // 新題的學習步驟中
+	PhaseRelearning = "relearning" // 答錯後的重新學習步驟中
+	PhaseReview     = "review"     // 已畢業，交給演算法排程
+)
+
+const minutesPerDay = 24 * 60
+
+// LearningSteps Anki 風格的學習步驟
+// 例如 Learning = [10m, 4h]：新題 10 分鐘後再做一次、接著 4 小時後，之後才交給演算法 (1 天)
+type LearningSteps struct {
+	Learning   []time.Duration // 新題的步驟
+	Relearning []time.Duration // 複習時答錯 (Lapse) 的步驟
+}
+
+// DefaultLearningSteps 10 分鐘 -> 4 小時 -> 交給演算法
+// 答錯的題目會在同一天晚上再出現
+func DefaultLearningSteps() LearningSteps {
+	return LearningSteps{
+		Learning:   []time.Duration{10 * time.Minute, 4 * time.Hour},
+		Relearning: []time.Duration{10 * time.Minute, 4 * time.Hour},
+	}
+}
+
+// reviewLearning 新題的學習步驟：
+// - Again: 回到第 1 步
+// - Hard:  重複目前的步驟
+// - Good:  進到下一步，走完所有步驟就畢業 (交給演算法)
+// - Easy:  直接畢業
+func (e *Engine) reviewLearning(key FuzzKey, input ReviewInput) ReviewOutput {
+	steps := e.steps.Learning
+	step := input.Step
+	if input.Phase == PhaseNew || step >= len(steps) {
+		step = 0
+	}
+
+	switch input.Grade {
+	case 0:
+		return stepOutput(input, passThrough(input), PhaseLearning, 0, steps[0])
+	case 1:
+		return stepOutput(input, passThrough(input), PhaseLearning, step, steps[step])
+	case 2:
+		if step+1 < len(steps) {
+			return stepOutput(input, passThrough(input), PhaseLearning, step+1, steps[step+1])
+		}
+	}
+
+	// 畢業：以一般複習的方式交給演算法 (Repetitions 仍為 0，SM-2 系列會給 1 天)
+	return e.schedule(key, input)
+}
+
+// reviewRelearning 答錯後的重新學習步驟
+// Lapse 當下演算法已經算好懲罰後的間隔 (存在 CurrentInterval)，畢業時直接使用
+func (e *Engine) reviewRelearning(input ReviewInput) ReviewOutput {
+	steps := e.steps.Relearning
+	step := input.Step
+	if step >= len(steps) {
+		step = 0
+	}
+
+	switch input.Grade {
+	case 0:
+		return stepOutput(input, passThrough(input), PhaseRelearning, 0, steps[0])
+	case 1:
+		return stepOutput(input, passThrough(input), PhaseRelearning, step, steps[step])
+	case 2:
+		if step+1 < len(steps) {
+			return stepOutput(input, passThrough(input), PhaseRelearning, step+1, steps[step+1])
+		}
+	}
+
+	// 畢業：回到複習階段
+	out := passThrough(input)
+	if out.Interval < 1 {
+		out.Interval = 1
+	}
+	next := out.Interval
+	if capped := CapToDeadline(input.ReviewedAt, input.Deadline, out.Interval); capped != out.Interval {
+		trace := &tracer{enabled: input.Explain, steps: out.Trace}
+		trace.add(TraceStep{Step: TraceDeadline, Input: float64(out.Interval), Output: float64(capped),
+			Note: "interview on " + input.Deadline.Format("2006-01-02")})
+		next = capped
+		out.Trace = trace.steps
+	}
+	out.Phase = PhaseReview
+	out.NextReviewAt = input.ReviewedAt.AddDate(0, 0, next)
+	out.IntervalMinutes = next * minutesPerDay
+	return out
+}
+
+// passThrough 學習步驟中不改變演算法的狀態
+func passThrough(input ReviewInput) ReviewOutput {
+	return ReviewOutput{
+		Interval:    input.CurrentInterval,
+		EaseFactor:  input.CurrentEF,
+		Repetitions: input.Repetitions,
+		Stability:   input.Stability,
+		Difficulty:  input.Difficulty,
+	}
+}
+
+// stepOutput 把結果改成「delay 之後在學習步驟中再出現」
+func stepOutput(input ReviewInput, out ReviewOutput, phase string, step int, delay time.Duration) ReviewOutput {
+	out.Phase = phase
+	out.Step = step
+	out.NextReviewAt = input.ReviewedAt.Add(delay)
+	out.IntervalMinutes = int(delay / time.Minute)
+
+	trace := &tracer{enabled: input.Explain, steps: out.Trace}
+	trace.add(TraceStep{Step: TraceLearningStep, Output: float64(out.IntervalMinutes),
+		Note: fmt.Sprintf("%s step %d (minutes)", phase, step+1)})
+	out.Trace = trace.steps
+	return out
+}
@@ -0,0 +1,118 @@
+package srs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEngineReviewLearningSteps(t *testing.T) {
+	reviewedAt := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	steps := DefaultLearningSteps()
+
+	tests := []struct {
+		name       string
+		steps      LearningSteps
+		input      ReviewInput
+		wantPhase  string
+		wantStep   int
+		wantLapsed bool
+		wantNext   time.Duration // NextReviewAt - ReviewedAt
+	}{
+		{
+			name:      "new card Good enters the second learning step",
+			steps:     steps,
+			input:     ReviewInput{Phase: PhaseNew, Grade: 2, CurrentEF: 2.5},
+			wantPhase: PhaseLearning,
+			wantStep:  1,
+			wantNext:  4 * time.Hour,
+		},
+		{
+			name:      "new card Easy skips the learning steps",
+			steps:     steps,
+			input:     ReviewInput{Phase: PhaseNew, Grade: 3, CurrentEF: 2.5},
+			wantPhase: PhaseReview,
+			wantNext:  24 * time.Hour,
+		},
+		{
+			name:      "learning Again goes back to the first step",
+			steps:     steps,
+			input:     ReviewInput{Phase: PhaseLearning, Step: 1, Grade: 0, CurrentEF: 2.5},
+			wantPhase: PhaseLearning,
+			wantStep:  0,
+			wantNext:  10 * time.Minute,
+		},
+		{
+			name:      "learning Hard repeats the current step",
+			steps:     steps,
+			input:     ReviewInput{Phase: PhaseLearning, Step: 1, Grade: 1, CurrentEF: 2.5},
+			wantPhase: PhaseLearning,
+			wantStep:  1,
+			wantNext:  4 * time.Hour,
+		},
+		{
+			name:      "last learning step Good graduates",
+			steps:     steps,
+			input:     ReviewInput{Phase: PhaseLearning, Step: 1, Grade: 2, CurrentEF: 2.5},
+			wantPhase: PhaseReview,
+			wantNext:  24 * time.Hour,
+		},
+		{
+			name:       "graduated card Again lapses into relearning",
+			steps:      steps,
+			input:      ReviewInput{Phase: PhaseReview, Repetitions: 3, CurrentInterval: 15, Grade: 0, CurrentEF: 2.5},
+			wantPhase:  PhaseRelearning,
+			wantStep:   0,
+			wantLapsed: true,
+			wantNext:   10 * time.Minute,
+		},
+		{
+			name:      "never-graduated card Again is not a lapse",
+			steps:     steps,
+			input:     ReviewInput{Phase: PhaseReview, Repetitions: 0, CurrentInterval: 1, Grade: 0, CurrentEF: 2.5},
+			wantPhase: PhaseLearning,
+			wantStep:  0,
+			wantNext:  10 * time.Minute,
+		},
+		{
+			name:      "relearning Good on the last step graduates with the stored interval",
+			steps:     steps,
+			input:     ReviewInput{Phase: PhaseRelearning, Step: 1, CurrentInterval: 3, Grade: 2, CurrentEF: 2.3},
+			wantPhase: PhaseReview,
+			wantNext:  3 * 24 * time.Hour,
+		},
+		{
+			name:       "lapse without steps stays in review",
+			input:      ReviewInput{Phase: PhaseReview, Repetitions: 2, CurrentInterval: 6, Grade: 0, CurrentEF: 2.5},
+			wantPhase:  PhaseReview,
+			wantLapsed: true,
+			wantNext:   24 * time.Hour,
+		},
+		{
+			name:      "review Good without steps",
+			input:     ReviewInput{Phase: PhaseReview, Repetitions: 1, CurrentInterval: 1, Grade: 2, CurrentEF: 2.5},
+			wantPhase: PhaseReview,
+			wantNext:  6 * 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(SM2{}, WithLearningSteps(tt.steps), WithClock(FixedClock(reviewedAt)))
+			tt.input.ReviewedAt = reviewedAt
+
+			out := engine.Review(FuzzKey{UserID: "u", QuestionID: "q"}, tt.input)
+			if out.Phase != tt.wantPhase {
+				t.Errorf("Phase = %q, want %q", out.Phase, tt.wantPhase)
+			}
+			if out.Step != tt.wantStep {
+				t.Errorf("Step = %d, want %d", out.Step, tt.wantStep)
+			}
+			if out.Lapsed != tt.wantLapsed {
+				t.Errorf("Lapsed = %v, want %v", out.Lapsed, tt.wantLapsed)
+			}
+			if got := out.NextReviewAt.Sub(reviewedAt); got != tt.wantNext {
+				t.Errorf("NextReviewAt - ReviewedAt = %v, want %v", got, tt.wantNext)
+			}
+		})
+	}
+}
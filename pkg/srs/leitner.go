@@ -0,0 +1,54 @@
+package srs
+
+// leitnerBoxIntervals 每個盒子對應的間隔 (天)，盒子編號從 1 開始
+var leitnerBoxIntervals = []int{1, 2, 4, 8, 16, 32, 64}
+
+// Leitner 是經典的 Leitner 卡片盒排程
+// 盒子編號存在 Repetitions 裡 (0 代表還沒進盒子)，EaseFactor 不使用、原值傳回
+type Leitner struct{}
+
+func (Leitner) Name() string { return AlgorithmLeitner }
+
+// Schedule 規則：
+// - Again: 回到第 1 盒
+// - Hard:  留在原本的盒子
+// - Good:  往上一盒
+// - Easy:  往上兩盒
+func (Leitner) Schedule(input ReviewInput) ReviewOutput {
+	box := input.Repetitions
+
+	switch input.Grade {
+	case 0:
+		box = 1
+	case 1:
+		// 留在原盒
+	case 2:
+		box++
+	case 3:
+		box += 2
+	}
+
+	if box < 1 {
+		box = 1
+	}
+	if box > len(leitnerBoxIntervals) {
+		box = len(leitnerBoxIntervals)
+	}
+
+	interval := leitnerBoxIntervals[box-1]
+
+	// Again 時 streak 歸零，但卡片仍在第 1 盒
+	repetitions := box
+	if input.Grade == 0 {
+		repetitions = 0
+	}
+
+	return ReviewOutput{
+		NextReviewAt: reviewTime(input).AddDate(0, 0, interval),
+		Interval:     interval,
+		EaseFactor:   input.CurrentEF,
+		Repetitions:  repetitions,
+		Stability:    input.Stability,
+		Difficulty:   input.Difficulty,
+	}
+}
@@ -0,0 +1,43 @@
+package srs
+
+import (
+	"math"
+	"time"
+)
+
+// MemoryState 估計記憶保留率所需的狀態 (對應 user_question_stats 的欄位)
+type MemoryState struct {
+	IntervalDays    int
+	IntervalMinutes int
+	Stability       float64 // FSRS 記憶穩定度，0 代表沒有 (SM-2 系列)
+	LastReviewedAt  time.Time
+}
+
+// Retrievability 估計在 at 這個時間點還記得這題的機率 (0~1)
+// - 有 FSRS 穩定度：使用 FSRS 的遺忘曲線
+// - 沒有：假設排定的間隔當天剛好是 90%，以指數曲線估計
+// 從來沒練習過的題目回傳 0
+func Retrievability(state MemoryState, at time.Time) float64 {
+	if state.LastReviewedAt.IsZero() {
+		return 0
+	}
+
+	elapsedDays := at.Sub(state.LastReviewedAt).Hours() / 24.0
+	if elapsedDays < 0 {
+		elapsedDays = 0
+	}
+
+	if state.Stability > 0 {
+		return forgettingCurve(elapsedDays, state.Stability)
+	}
+
+	intervalDays := float64(state.IntervalDays)
+	if state.IntervalMinutes > 0 {
+		intervalDays = float64(state.IntervalMinutes) / minutesPerDay
+	}
+	if intervalDays <= 0 {
+		return 0
+	}
+
+	return math.Pow(0.9, elapsedDays/intervalDays)
+}
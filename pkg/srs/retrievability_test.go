@@ -0,0 +1,35 @@
+package srs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRetrievability(t *testing.T) {
+	last := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		state MemoryState
+		at    time.Time
+		want  float64
+	}{
+		{name: "never reviewed", state: MemoryState{IntervalDays: 10}, at: last, want: 0},
+		{name: "just reviewed", state: MemoryState{IntervalDays: 10, LastReviewedAt: last}, at: last, want: 1},
+		{name: "on the due date", state: MemoryState{IntervalDays: 10, LastReviewedAt: last}, at: last.AddDate(0, 0, 10), want: 0.9},
+		{name: "twice the interval", state: MemoryState{IntervalDays: 10, LastReviewedAt: last}, at: last.AddDate(0, 0, 20), want: 0.81},
+		{name: "learning step in minutes", state: MemoryState{IntervalMinutes: 240, LastReviewedAt: last}, at: last.Add(4 * time.Hour), want: 0.9},
+		{name: "FSRS stability", state: MemoryState{IntervalDays: 3, Stability: 20, LastReviewedAt: last}, at: last.AddDate(0, 0, 20), want: 0.9},
+		{name: "clock before the last review", state: MemoryState{IntervalDays: 10, LastReviewedAt: last}, at: last.Add(-time.Hour), want: 1},
+		{name: "no interval", state: MemoryState{LastReviewedAt: last}, at: last.AddDate(0, 0, 1), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Retrievability(tt.state, tt.at); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Retrievability() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
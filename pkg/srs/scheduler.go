@@ -0,0 +1,56 @@
+package srs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 演算法名稱 (存在 user_settings.algorithm)
+const (
+	AlgorithmLeTrackerSM2 = "letracker-sm2" // LeTracker 改良版 SM-2 (預設)
+	AlgorithmSM2          = "sm2"           // 原版 SuperMemo-2
+	AlgorithmLeitner      = "leitner"       // 經典 Leitner 卡片盒
+	AlgorithmFSRS         = "fsrs"          // Free Spaced Repetition Scheduler (記憶模型)
+)
+
+// DefaultAlgorithm 使用者沒有設定時使用的演算法
+const DefaultAlgorithm = AlgorithmLeTrackerSM2
+
+// ErrUnknownAlgorithm 傳入未知的演算法名稱
+var ErrUnknownAlgorithm = errors.New("unknown scheduling algorithm")
+
+// Scheduler 是所有排程演算法的共同介面
+// 輸入目前狀態 + 本次評分，回傳下一次的排程結果
+type Scheduler interface {
+	// Name 回傳演算法名稱 (對應上面的 Algorithm* 常數)
+	Name() string
+	// Schedule 計算下一次複習
+	Schedule(input ReviewInput) ReviewOutput
+}
+
+// Fuzzable 演算法可選擇實作：回傳 true 代表 Engine 要對長間隔加入 Fuzz 與負載平衡
+// 沒有實作的演算法照原樣排程 (例如 Leitner 的固定盒子、作為比較基準的原版 SM-2)
+type Fuzzable interface {
+	Fuzzable() bool
+}
+
+// NewScheduler 依名稱建立對應的 Scheduler，空字串代表預設演算法
+func NewScheduler(name string) (Scheduler, error) {
+	switch name {
+	case "", AlgorithmLeTrackerSM2:
+		return LeTrackerSM2{}, nil
+	case AlgorithmSM2:
+		return SM2{}, nil
+	case AlgorithmLeitner:
+		return Leitner{}, nil
+	case AlgorithmFSRS:
+		return NewFSRS(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+	}
+}
+
+// Algorithms 回傳所有可用的演算法名稱
+func Algorithms() []string {
+	return []string{AlgorithmLeTrackerSM2, AlgorithmSM2, AlgorithmLeitner, AlgorithmFSRS}
+}
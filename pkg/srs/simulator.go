@@ -0,0 +1,142 @@
+package srs
+
+import (
+	"math/rand"
+	"time"
+)
+
+// CardState 一題目前的排程狀態 (對應 user_question_stats)
+type CardState struct {
+	IntervalDays    int
+	IntervalMinutes int
+	EaseFactor      float64
+	Repetitions     int
+	Stability       float64
+	Difficulty      float64
+	Phase           string
+	Step            int
+	ReviewCount     int
+	NextReviewAt    time.Time
+	LastReviewedAt  time.Time
+}
+
+// GradeDistribution 每種評分出現的機率 [Again, Hard, Good, Easy]，總和不必為 1
+type GradeDistribution [4]float64
+
+// DefaultGradeDistribution 沒有歷史紀錄時使用
+var DefaultGradeDistribution = GradeDistribution{0.1, 0.15, 0.6, 0.15}
+
+// DefaultSecondsPerGrade 每種評分平均花費的時間 (秒)：越難想起來的題目花越久
+var DefaultSecondsPerGrade = [4]float64{45 * 60, 35 * 60, 25 * 60, 15 * 60}
+
+// ForecastOptions 模擬設定
+type ForecastOptions struct {
+	Start           time.Time
+	Days            int        // 模擬幾天 (例如 30 / 90 / 180)
+	Runs            int        // Monte Carlo 次數
+	NewPerDay       int        // 每天新增幾題新題目
+	Seed            int64      // 亂數種子 (同樣的種子得到同樣的結果)
+	SecondsPerGrade [4]float64 // 每種評分平均花費的秒數
+	// 使用者的排程設定 (間隔上限、Fuzz 門檻與範圍)，與即時排程相同
+	Config SchedulerConfig
+}
+
+// DayForecast 單日的預測
+type DayForecast struct {
+	Date            time.Time
+	ExpectedReviews float64 // 平均複習題數 (含當天的新題)
+	ExpectedSeconds float64 // 平均花費秒數
+}
+
+// Forecast 模擬結果
+type Forecast struct {
+	Days         []DayForecast
+	TotalReviews float64
+	TotalSeconds float64
+	PeakReviews  float64
+	PeakDate     time.Time
+}
+
+// Simulate 以 Monte Carlo 模擬未來 opts.Days 天的複習量
+// 每一輪：每天把到期的題目各複習一次，依 dist 抽出評分，交給 scheduler 排下一次
+func Simulate(scheduler Scheduler, cards []CardState, dist GradeDistribution, opts ForecastOptions) Forecast {
+	if opts.Runs <= 0 {
+		opts.Runs = 1
+	}
+	if opts.SecondsPerGrade == ([4]float64{}) {
+		opts.SecondsPerGrade = DefaultSecondsPerGrade
+	}
+
+	start := time.Date(opts.Start.Year(), opts.Start.Month(), opts.Start.Day(), 0, 0, 0, 0, opts.Start.Location())
+	reviews := make([]float64, opts.Days)
+	seconds := make([]float64, opts.Days)
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	engine := NewEngine(scheduler, WithClock(FixedClock(start)), WithFuzz(randFuzz{rng}), WithConfig(opts.Config))
+
+	for run := 0; run < opts.Runs; run++ {
+		deck := append([]CardState(nil), cards...)
+
+		for day := 0; day < opts.Days; day++ {
+			dayStart := start.AddDate(0, 0, day)
+			dayEnd := dayStart.AddDate(0, 0, 1)
+
+			for i := 0; i < opts.NewPerDay; i++ {
+				deck = append(deck, CardState{EaseFactor: 2.5, NextReviewAt: dayStart})
+			}
+
+			for i := range deck {
+				if !deck[i].NextReviewAt.Before(dayEnd) {
+					continue
+				}
+
+				grade := dist.sample(rng)
+				deck[i], _ = engine.apply(deck[i], ReviewEvent{At: dayStart, Grade: grade}, TimelineOptions{})
+
+				reviews[day]++
+				seconds[day] += opts.SecondsPerGrade[grade]
+			}
+		}
+	}
+
+	forecast := Forecast{Days: make([]DayForecast, opts.Days)}
+	for day := range forecast.Days {
+		d := DayForecast{
+			Date:            start.AddDate(0, 0, day),
+			ExpectedReviews: reviews[day] / float64(opts.Runs),
+			ExpectedSeconds: seconds[day] / float64(opts.Runs),
+		}
+		forecast.Days[day] = d
+		forecast.TotalReviews += d.ExpectedReviews
+		forecast.TotalSeconds += d.ExpectedSeconds
+		if d.ExpectedReviews > forecast.PeakReviews {
+			forecast.PeakReviews = d.ExpectedReviews
+			forecast.PeakDate = d.Date
+		}
+	}
+
+	return forecast
+}
+
+// sample 依機率抽出一個評分
+func (d GradeDistribution) sample(rng *rand.Rand) int {
+	total := d[0] + d[1] + d[2] + d[3]
+	if total <= 0 {
+		d = DefaultGradeDistribution
+		total = 1
+	}
+
+	x := rng.Float64() * total
+	for grade, p := range d {
+		if x < p {
+			return grade
+		}
+		x -= p
+	}
+	return 3
+}
+
+// randFuzz 模擬時使用帶種子的亂數
+type randFuzz struct{ rng *rand.Rand }
+
+func (f randFuzz) Float64(FuzzKey) float64 { return f.rng.Float64() }
@@ -0,0 +1,84 @@
+package srs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSimulate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	cards := []CardState{
+		{IntervalDays: 3, EaseFactor: 2.5, Repetitions: 2, Phase: PhaseReview, NextReviewAt: start, LastReviewedAt: start.AddDate(0, 0, -3)},
+		{IntervalDays: 20, EaseFactor: 2.5, Repetitions: 4, Phase: PhaseReview, NextReviewAt: start.AddDate(0, 0, 5), LastReviewedAt: start.AddDate(0, 0, -15)},
+	}
+	opts := ForecastOptions{Start: start, Days: 30, Runs: 20, Seed: 7}
+
+	forecast := Simulate(LeTrackerSM2{}, cards, DefaultGradeDistribution, opts)
+	if len(forecast.Days) != 30 {
+		t.Fatalf("got %d days, want 30", len(forecast.Days))
+	}
+	if want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC); !forecast.Days[0].Date.Equal(want) {
+		t.Errorf("first day = %v, want the start of the day %v", forecast.Days[0].Date, want)
+	}
+	if forecast.Days[0].ExpectedReviews != 1 {
+		t.Errorf("first day reviews = %v, want 1 (only the first card is due)", forecast.Days[0].ExpectedReviews)
+	}
+
+	var total float64
+	for _, d := range forecast.Days {
+		total += d.ExpectedReviews
+		if d.ExpectedReviews > forecast.PeakReviews {
+			t.Errorf("%v has %v reviews, above the peak %v", d.Date, d.ExpectedReviews, forecast.PeakReviews)
+		}
+	}
+	if total != forecast.TotalReviews {
+		t.Errorf("TotalReviews = %v, want the sum of the days %v", forecast.TotalReviews, total)
+	}
+
+	// 同樣的種子得到同樣的預測
+	if again := Simulate(LeTrackerSM2{}, cards, DefaultGradeDistribution, opts); !reflect.DeepEqual(again, forecast) {
+		t.Error("Simulate() with the same seed should give the same forecast")
+	}
+}
+
+func TestSimulateNewCardsAndSeconds(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 全部 Good，每次 10 分鐘
+	opts := ForecastOptions{
+		Start:           start,
+		Days:            3,
+		NewPerDay:       2,
+		SecondsPerGrade: [4]float64{0, 0, 600, 0},
+	}
+
+	forecast := Simulate(SM2{}, nil, GradeDistribution{0, 0, 1, 0}, opts)
+	// SM-2 新題第一次複習後隔天到期，所以第二天之後每天是 2 題新題 + 前一天的 2 題
+	want := []float64{2, 4, 4}
+	for day, reviews := range want {
+		if got := forecast.Days[day].ExpectedReviews; got != reviews {
+			t.Errorf("day %d reviews = %v, want %v", day, got, reviews)
+		}
+		if got := forecast.Days[day].ExpectedSeconds; got != reviews*600 {
+			t.Errorf("day %d seconds = %v, want %v", day, got, reviews*600)
+		}
+	}
+}
+
+func TestSimulateUsesConfig(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cards := []CardState{{IntervalDays: 30, EaseFactor: 2.5, Repetitions: 5, Phase: PhaseReview, NextReviewAt: start, LastReviewedAt: start.AddDate(0, 0, -30)}}
+	easy := GradeDistribution{0, 0, 0, 1}
+
+	// 預設的間隔上限下，答 Easy 的長間隔題目 60 天內只會再出現在第一天
+	forecast := Simulate(LeTrackerSM2{}, cards, easy, ForecastOptions{Start: start, Days: 60})
+	if forecast.TotalReviews != 1 {
+		t.Errorf("TotalReviews = %v, want 1 with the default maximum interval", forecast.TotalReviews)
+	}
+
+	// 間隔上限 7 天：每 7 天複習一次
+	forecast = Simulate(LeTrackerSM2{}, cards, easy, ForecastOptions{Start: start, Days: 60, Config: SchedulerConfig{MaximumInterval: 7}})
+	if forecast.TotalReviews != 9 {
+		t.Errorf("TotalReviews = %v, want 9 with a 7-day maximum interval", forecast.TotalReviews)
+	}
+}
@@ -0,0 +1,61 @@
+package srs
+
+import "math"
+
+// SM2 是原版 SuperMemo-2 演算法，沒有任何 LeTracker 的改良
+// 用來跟改良版做比較；Config 只用到 EF 底限 (零值為 1.3)
+type SM2 struct {
+	Config SchedulerConfig
+}
+
+func (SM2) Name() string { return AlgorithmSM2 }
+
+// Schedule 依照 SM-2 原始論文的規則計算
+// 我們的 Grade (0-3) 對應到 SM-2 的品質分數 q (0-5)：
+// Again=2, Hard=3, Good=4, Easy=5
+func (s SM2) Schedule(input ReviewInput) ReviewOutput {
+	q := input.Grade + 2
+
+	// q < 3：重新開始，但不改變 EF
+	if q < 3 {
+		return ReviewOutput{
+			NextReviewAt: reviewTime(input).AddDate(0, 0, 1),
+			Interval:     1,
+			EaseFactor:   input.CurrentEF,
+			Repetitions:  0,
+			Stability:    input.Stability,
+			Difficulty:   input.Difficulty,
+		}
+	}
+
+	// EF' = EF + (0.1 - (5-q) * (0.08 + (5-q) * 0.02))
+	newEF := input.CurrentEF + (0.1 - float64(5-q)*(0.08+float64(5-q)*0.02))
+	if minEF := s.Config.WithDefaults().MinimumEF; newEF < minEF {
+		newEF = minEF
+	}
+
+	newRepetitions := input.Repetitions + 1
+
+	var newInterval int
+	switch newRepetitions {
+	case 1:
+		newInterval = 1
+	case 2:
+		newInterval = 6
+	default:
+		newInterval = int(math.Round(float64(input.CurrentInterval) * newEF))
+	}
+
+	if newInterval < 1 {
+		newInterval = 1
+	}
+
+	return ReviewOutput{
+		NextReviewAt: reviewTime(input).AddDate(0, 0, newInterval),
+		Interval:     newInterval,
+		EaseFactor:   newEF,
+		Repetitions:  newRepetitions,
+		Stability:    input.Stability,
+		Difficulty:   input.Difficulty,
+	}
+}
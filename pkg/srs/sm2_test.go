@@ -0,0 +1,47 @@
+package srs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSM2Schedule(t *testing.T) {
+	reviewedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		config       SchedulerConfig
+		input        ReviewInput
+		wantInterval int
+		wantEF       float64
+		wantReps     int
+	}{
+		{name: "first success", input: ReviewInput{Grade: 2, CurrentEF: 2.5}, wantInterval: 1, wantEF: 2.5, wantReps: 1},
+		{name: "second success", input: ReviewInput{Grade: 2, CurrentEF: 2.5, Repetitions: 1, CurrentInterval: 1}, wantInterval: 6, wantEF: 2.5, wantReps: 2},
+		{name: "later success multiplies by EF", input: ReviewInput{Grade: 3, CurrentEF: 2.5, Repetitions: 2, CurrentInterval: 6}, wantInterval: 16, wantEF: 2.6, wantReps: 3},
+		{name: "Hard lowers EF", input: ReviewInput{Grade: 1, CurrentEF: 2.5, Repetitions: 2, CurrentInterval: 6}, wantInterval: 14, wantEF: 2.36, wantReps: 3},
+		{name: "Again restarts and keeps EF", input: ReviewInput{Grade: 0, CurrentEF: 2.1, Repetitions: 4, CurrentInterval: 30}, wantInterval: 1, wantEF: 2.1, wantReps: 0},
+		{name: "default EF floor", input: ReviewInput{Grade: 1, CurrentEF: 1.35, Repetitions: 3, CurrentInterval: 10}, wantInterval: 13, wantEF: 1.3, wantReps: 4},
+		{name: "configured EF floor", config: SchedulerConfig{MinimumEF: 1.7}, input: ReviewInput{Grade: 1, CurrentEF: 1.75, Repetitions: 3, CurrentInterval: 10}, wantInterval: 17, wantEF: 1.7, wantReps: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.ReviewedAt = reviewedAt
+			out := SM2{Config: tt.config}.Schedule(tt.input)
+			if out.Interval != tt.wantInterval {
+				t.Errorf("Interval = %d, want %d", out.Interval, tt.wantInterval)
+			}
+			if math.Abs(out.EaseFactor-tt.wantEF) > 1e-9 {
+				t.Errorf("EaseFactor = %v, want %v", out.EaseFactor, tt.wantEF)
+			}
+			if out.Repetitions != tt.wantReps {
+				t.Errorf("Repetitions = %d, want %d", out.Repetitions, tt.wantReps)
+			}
+			if want := reviewedAt.AddDate(0, 0, tt.wantInterval); !out.NextReviewAt.Equal(want) {
+				t.Errorf("NextReviewAt = %v, want %v", out.NextReviewAt, want)
+			}
+		})
+	}
+}
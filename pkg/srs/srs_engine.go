@@ -1,8 +1,8 @@
 package srs
 
 import (
+	"fmt"
 	"math"
-	"math/rand"
 	"time"
 )
 
@@ -13,22 +13,112 @@ type ReviewInput struct {
 	Repetitions     int     // 連續答對次數 (Streak)
 	Grade           int     // 0: Again, 1: Hard, 2: Good, 3: Easy
 
-	// ActualDays 是本次練習距離上次練習的「實際天數」
-	// - 在「一般刷題模式」下，通常不需要傳 (或是傳 0)，我們會忽略它。
-	// - 在「歷史回放模式」下，這是關鍵參數，用來判斷是否給予 "Long-term Retention Bonus"。
+	// ActualDays 是本次練習距離上次練習的「實際天數」(0 代表未知)
+	// 一般刷題與歷史回放都會傳入，用來判斷：
+	// - 提早複習：成長打折 (實際天數 / 預定間隔)
+	// - 晚做又答對：延遲的一部分算進間隔，隔太久 (> 1.5 倍) 再給 "Long-term Retention Bonus"
 	ActualDays float64
+
+	// FSRS 記憶狀態 (0 代表尚未建立)，SM-2 系列的演算法會原值傳回
+	Stability  float64 // 記憶穩定度 (天)
+	Difficulty float64 // 題目難度 1~10
+
+	// ReviewedAt 本次練習的時間，NextReviewAt 以此為錨點
+	// - 回放模式傳入歷史紀錄的時間
+	// - 零值代表「現在」(由 Engine 的 Clock 決定)
+	ReviewedAt time.Time
+
+	// 學習步驟的狀態 (只有 Engine 啟用 LearningSteps 時才有意義)
+	Phase string // PhaseNew / PhaseLearning / PhaseRelearning / PhaseReview
+	Step  int    // 目前在第幾個學習步驟 (從 0 開始)
+
+	// Deadline 面試日期 (零值代表沒有設定)
+	// 設定後，會跳過面試前最後一週的排程提前到最後一週內 (間隔照存)，確保面試前還會再複習一次
+	Deadline time.Time
+
+	// Explain 為 true 時，ReviewOutput.Trace 會記錄每一步的計算過程
+	Explain bool
+
+	// SecondIntervals 這題專屬的第二次複習間隔 Hard / Good / Easy (例如依題目難度調整)
+	// 零值使用 SchedulerConfig 的設定
+	SecondIntervals [3]int
 }
 
 // ReviewOutput 計算結果
 type ReviewOutput struct {
 	NextReviewAt time.Time
-	Interval     int
+	Interval     int // 複習間隔 (天)；學習步驟中代表畢業後要用的間隔
 	EaseFactor   float64
 	Repetitions  int
+	Stability    float64
+	Difficulty   float64
+
+	// IntervalMinutes 距離下一次複習的分鐘數 (學習步驟是分鐘級的，一般複習 = Interval * 1440，面試模式提前時會比較短)
+	// 只有經過 Engine 才會填入
+	IntervalMinutes int
+	Phase           string
+	Step            int
+
+	// Lapsed 已畢業 (複習階段) 的題目這次答錯，用來累計 lapse 次數
+	Lapsed bool
+
+	// Trace 計算過程 (只有 ReviewInput.Explain 為 true 時才有)
+	Trace []TraceStep
+}
+
+// LeTrackerParams 改良版 SM-2 裡可調整的常數
+// 預設值是經驗猜測，可以用 pkg/optimizer 依照使用者的歷史紀錄擬合
+type LeTrackerParams struct {
+	HardModifier   float64 // Hard 的間隔倍率 (預設 0.8)
+	EasyBonus      float64 // Easy 的間隔倍率 (預設 1.1)
+	RetentionBonus float64 // 長期記憶獎勵倍率 (預設 1.5)
 }
 
-// CalculateNextReview 執行 LeTracker 專屬改良演算法 (含回放邏輯)
+// DefaultLeTrackerParams 回傳預設參數
+func DefaultLeTrackerParams() LeTrackerParams {
+	return LeTrackerParams{
+		HardModifier:   0.8,
+		EasyBonus:      1.1,
+		RetentionBonus: 1.5,
+	}
+}
+
+// LeTrackerSM2 是 LeTracker 專屬的改良版 SM-2 (預設演算法)
+// Params 為零值時使用預設參數；Config 裡有設定倍率時以 Config 為準
+type LeTrackerSM2 struct {
+	Params LeTrackerParams
+	Config SchedulerConfig
+}
+
+func (LeTrackerSM2) Name() string { return AlgorithmLeTrackerSM2 }
+
+// Fuzzable 改良版 SM-2 一直都有 Fuzz (原本寫在演算法裡，現在交給 Engine)
+func (LeTrackerSM2) Fuzzable() bool { return true }
+
+// CalculateNextReview 以預設參數執行 LeTracker 專屬改良演算法
+// 保留給舊程式使用：系統時鐘 + 隨機 Fuzz，結果不可重現
+// 需要可重現的結果請使用 NewEngine
 func CalculateNextReview(input ReviewInput) ReviewOutput {
+	return legacyEngine.Review(FuzzKey{}, input)
+}
+
+var legacyEngine = NewEngine(LeTrackerSM2{}, WithFuzz(RandomFuzz{}))
+
+// Schedule 執行 LeTracker 專屬改良演算法 (含回放邏輯)
+func (s LeTrackerSM2) Schedule(input ReviewInput) ReviewOutput {
+	params := s.Params
+	if params == (LeTrackerParams{}) {
+		params = DefaultLeTrackerParams()
+	}
+	cfg := s.Config.WithDefaults()
+	if cfg.HardModifier > 0 {
+		params.HardModifier = cfg.HardModifier
+	}
+	if cfg.EasyBonus > 0 {
+		params.EasyBonus = cfg.EasyBonus
+	}
+	trace := newTracer(input)
+
 	// 初始化隨機數種子 (建議在 main init 做，這裡為了安全起見保留)
 	// rand.Seed(time.Now().UnixNano())
 
@@ -36,11 +126,18 @@ func CalculateNextReview(input ReviewInput) ReviewOutput {
 	// 邏輯 1: 處理 "Again" (重做)
 	// ---------------------------------------------------------
 	if input.Grade == 0 {
+		newEF := math.Max(cfg.MinimumEF, input.CurrentEF-0.2) // 懲罰 EF 但設底限
+		trace.add(TraceStep{Step: TraceEF, Input: input.CurrentEF, Output: newEF, Note: "again: -0.2"})
+		trace.add(TraceStep{Step: TraceBaseInterval, Input: float64(input.CurrentInterval), Output: 1, Note: "again: reset to 1 day"})
+
 		return ReviewOutput{
-			NextReviewAt: time.Now().AddDate(0, 0, 1), // 明天立刻做
+			NextReviewAt: reviewTime(input).AddDate(0, 0, 1), // 明天立刻做
 			Interval:     1,
-			EaseFactor:   math.Max(1.3, input.CurrentEF-0.2), // 懲罰 EF 但設底限
-			Repetitions:  0,                                  // 重置 streak
+			EaseFactor:   newEF,
+			Repetitions:  0, // 重置 streak
+			Stability:    input.Stability,
+			Difficulty:   input.Difficulty,
+			Trace:        trace.steps,
 		}
 	}
 
@@ -49,10 +146,17 @@ func CalculateNextReview(input ReviewInput) ReviewOutput {
 	// ---------------------------------------------------------
 	// 公式：EF' = EF + (0.1 - (3-Grade) * (0.08 + (3-Grade) * 0.02))
 	newEF := input.CurrentEF + (0.1 - float64(3-input.Grade)*(0.08+float64(3-input.Grade)*0.02))
-	if newEF < 1.3 {
-		newEF = 1.3 // EF 底限
+	if newEF < cfg.MinimumEF {
+		newEF = cfg.MinimumEF // EF 底限
 	}
 
+	// [提早複習]: 還沒到期就做對，只證明記得「到目前為止」，EF 的提升打折
+	credit := earlyCredit(input)
+	if credit < 1 && newEF > input.CurrentEF {
+		newEF = input.CurrentEF + (newEF-input.CurrentEF)*credit
+	}
+	trace.add(TraceStep{Step: TraceEF, Input: input.CurrentEF, Output: newEF})
+
 	// ---------------------------------------------------------
 	// 邏輯 3: 計算新的 Interval (天數)
 	// ---------------------------------------------------------
@@ -61,59 +165,74 @@ func CalculateNextReview(input ReviewInput) ReviewOutput {
 
 	if newRepetitions == 1 {
 		newInterval = 1
+		trace.add(TraceStep{Step: TraceBaseInterval, Output: 1, Note: "first review"})
 	} else if newRepetitions == 2 {
 		// [早期階段細緻化]
-		switch input.Grade {
-		case 1: // Hard
-			newInterval = 3 // 3天後再見
-		case 2: // Good
-			newInterval = 5
-		case 3: // Easy
-			newInterval = 7
-		default:
-			newInterval = 4
+		// 預設 Hard 3 天、Good 5 天、Easy 7 天後再見 (可依題目難度調整)
+		second, note := cfg.SecondIntervals, "second review"
+		if input.SecondIntervals != ([3]int{}) {
+			second, note = input.SecondIntervals, "second review (per question)"
 		}
+		newInterval = second[max(clampGrade(input.Grade), 1)-1]
+		trace.add(TraceStep{Step: TraceBaseInterval, Output: float64(newInterval), Note: note})
 	} else {
 		// [後期階段計算]
 
 		// A. 基礎計算 (Interval * EF)
-		baseInterval := float64(input.CurrentInterval) * newEF
+		// [晚做又答對]: 實際上記得比預定的間隔更久，把延遲的一部分算進上次的間隔
+		prevInterval := float64(input.CurrentInterval)
+		if late := input.ActualDays - prevInterval; input.CurrentInterval > 0 && late > 0 && input.Grade >= 2 {
+			effective := prevInterval + late*lateCreditShare
+			trace.add(TraceStep{Step: TraceLateReview, Input: prevInterval, Output: effective,
+				Note: fmt.Sprintf("%.1f days late", late)})
+			prevInterval = effective
+		}
+
+		baseInterval := prevInterval * newEF
+		trace.add(TraceStep{Step: TraceBaseInterval, Input: prevInterval, Factor: newEF, Output: baseInterval})
 
 		// B. Hard 懲罰 & Easy 獎勵
 		modifier := 1.0
 		if input.Grade == 1 {
-			modifier = 0.8 // Hard 打 8 折
+			modifier = params.HardModifier // Hard 打折 (預設 8 折)
 		} else if input.Grade == 3 {
-			modifier = 1.1 // Easy 給 1.1 倍
+			modifier = params.EasyBonus // Easy 加成 (預設 1.1 倍)
 		}
 
 		calculatedDays := baseInterval * modifier
+		trace.add(TraceStep{Step: TraceModifier, Input: baseInterval, Factor: modifier, Output: calculatedDays, Note: gradeNames[clampGrade(input.Grade)]})
 
-		// C. [回放模式專屬]: 長期記憶獎勵 (Retention Bonus)
-		// 如果使用者在歷史紀錄中，實際隔了很久(ActualDays)才做且做對了，
+		// C. 長期記憶獎勵 (Retention Bonus)，回放與即時練習都適用
+		// 如果使用者實際隔了很久(ActualDays)才做且做對了，
 		// 代表他記憶很深，我們應該大幅拉長下一次間隔。
 		if input.ActualDays > 0 && input.Grade >= 2 {
 			// 如果 實際間隔 > 預定間隔 的 1.5 倍
 			if input.CurrentInterval > 0 && input.ActualDays > float64(input.CurrentInterval)*1.5 {
-				// 給予 1.5 倍的額外獎勵 (這是一個激進但合理的策略)
-				calculatedDays = math.Max(calculatedDays, input.ActualDays*1.5)
+				// 給予額外獎勵 (預設 1.5 倍，這是一個激進但合理的策略)
+				before := calculatedDays
+				calculatedDays = math.Max(calculatedDays, input.ActualDays*params.RetentionBonus)
+				trace.add(TraceStep{Step: TraceRetentionBonus, Input: before, Factor: params.RetentionBonus, Output: calculatedDays,
+					Note: fmt.Sprintf("actual %.1f days > 1.5 x interval %d", input.ActualDays, input.CurrentInterval)})
 
 				// 並且因為表現優異，稍微提升 EF
 				newEF += 0.15
+				trace.add(TraceStep{Step: TraceEF, Input: newEF - 0.15, Output: newEF, Note: "retention bonus: +0.15"})
 			}
 		}
 
+		// D. [提早複習]: 只給部分的成長，但不會比原本的間隔短
+		if credit < 1 {
+			damped := float64(input.CurrentInterval) + (calculatedDays-float64(input.CurrentInterval))*credit
+			damped = math.Max(damped, float64(input.CurrentInterval))
+			trace.add(TraceStep{Step: TraceEarlyReview, Input: calculatedDays, Factor: credit, Output: damped,
+				Note: fmt.Sprintf("reviewed after %.1f of %d days", input.ActualDays, input.CurrentInterval)})
+			calculatedDays = damped
+		}
+
 		newInterval = int(math.Round(calculatedDays))
 	}
 
-	// ---------------------------------------------------------
-	// 邏輯 4: Fuzzing (模糊化) - 防止題目堆積
-	// ---------------------------------------------------------
-	// 當間隔大於 10 天時，加入 ±5% 的隨機波動
-	if newInterval > 10 {
-		fuzzFactor := 0.95 + rand.Float64()*0.1
-		newInterval = int(math.Round(float64(newInterval) * fuzzFactor))
-	}
+	// (Fuzzing 由 Engine 統一處理)
 
 	// 確保至少間隔 1 天
 	if newInterval < 1 {
@@ -121,9 +240,30 @@ func CalculateNextReview(input ReviewInput) ReviewOutput {
 	}
 
 	return ReviewOutput{
-		NextReviewAt: time.Now().AddDate(0, 0, newInterval),
+		NextReviewAt: reviewTime(input).AddDate(0, 0, newInterval),
 		Interval:     newInterval,
 		EaseFactor:   newEF,
 		Repetitions:  newRepetitions,
+		Stability:    input.Stability,
+		Difficulty:   input.Difficulty,
+		Trace:        trace.steps,
+	}
+}
+
+// lateCreditShare 晚做又答對時，延遲的天數有多少比例算進上次的間隔
+const lateCreditShare = 0.5
+
+// earlyCredit 提早複習 (還沒到期) 且答對時的成長比例 = 實際天數 / 預定間隔
+// 沒有提早、答錯或是 Hard 都回傳 1 (不打折)
+func earlyCredit(input ReviewInput) float64 {
+	if input.Grade < 2 || input.ActualDays <= 0 || input.CurrentInterval <= 0 {
+		return 1
 	}
+	if input.ActualDays >= float64(input.CurrentInterval) {
+		return 1
+	}
+	return input.ActualDays / float64(input.CurrentInterval)
 }
+
+// gradeNames 評分的名稱 (給 Trace 使用)
+var gradeNames = [4]string{"again", "hard", "good", "easy"}
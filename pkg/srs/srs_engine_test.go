@@ -0,0 +1,49 @@
+package srs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEarlyCredit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input ReviewInput
+		want  float64
+	}{
+		{name: "on time", input: ReviewInput{Grade: 2, CurrentInterval: 10, ActualDays: 10}, want: 1},
+		{name: "late", input: ReviewInput{Grade: 3, CurrentInterval: 10, ActualDays: 14}, want: 1},
+		{name: "half way", input: ReviewInput{Grade: 2, CurrentInterval: 10, ActualDays: 5}, want: 0.5},
+		{name: "early Easy", input: ReviewInput{Grade: 3, CurrentInterval: 8, ActualDays: 2}, want: 0.25},
+		{name: "early Hard gets no partial credit", input: ReviewInput{Grade: 1, CurrentInterval: 10, ActualDays: 5}, want: 1},
+		{name: "early Again", input: ReviewInput{Grade: 0, CurrentInterval: 10, ActualDays: 5}, want: 1},
+		{name: "unknown elapsed time", input: ReviewInput{Grade: 2, CurrentInterval: 10}, want: 1},
+		{name: "new card", input: ReviewInput{Grade: 2, ActualDays: 3}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := earlyCredit(tt.input); got != tt.want {
+				t.Errorf("earlyCredit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeTrackerSM2EarlyReviewGrowsLess(t *testing.T) {
+	reviewedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := ReviewInput{Grade: 2, CurrentInterval: 20, CurrentEF: 2.5, Repetitions: 4, ReviewedAt: reviewedAt}
+	schedule := func(actualDays float64) int {
+		input := base
+		input.ActualDays = actualDays
+		return LeTrackerSM2{}.Schedule(input).Interval
+	}
+
+	early, onTime := schedule(5), schedule(20)
+	if !(early < onTime) {
+		t.Errorf("early review interval = %d, want less than on time %d", early, onTime)
+	}
+	if early < base.CurrentInterval {
+		t.Errorf("early review interval = %d, should not shrink below the current %d", early, base.CurrentInterval)
+	}
+}
@@ -0,0 +1,104 @@
+package srs
+
+import (
+	"iter"
+	"slices"
+	"time"
+)
+
+// ReviewEvent 一次已評分的練習
+type ReviewEvent struct {
+	At    time.Time
+	Grade int // 0: Again, 1: Hard, 2: Good, 3: Easy
+}
+
+// TimelineOptions 批次回放的設定
+type TimelineOptions struct {
+	// 產生可重現的 Fuzz (第幾次複習由 CardState.ReviewCount 決定)
+	UserID     string
+	QuestionID string
+	// 距離上次排程不到 MinGap 的練習只記錄、不重新排程 (例如同一天刷多次)，0 代表每次都排程
+	MinGap          time.Duration
+	Deadline        time.Time
+	SecondIntervals [3]int
+	Explain         bool
+}
+
+// Snapshot 一次練習之後的狀態
+type Snapshot struct {
+	Event ReviewEvent
+	State CardState
+	// 演算法的完整輸出 (含 Trace / Lapsed)，Skipped 時為零值
+	Output  ReviewOutput
+	Skipped bool
+}
+
+// Timeline 依序回放 events，回傳每一次練習之後的狀態
+// events 必須按時間從舊到新排序
+func (e *Engine) Timeline(initial CardState, events []ReviewEvent, opts TimelineOptions) []Snapshot {
+	snapshots := make([]Snapshot, 0, len(events))
+	for snapshot := range e.Stream(initial, slices.Values(events), opts) {
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
+
+// Stream 與 Timeline 相同，但逐筆產生 Snapshot，不必把整段歷史放在記憶體裡
+func (e *Engine) Stream(initial CardState, events iter.Seq[ReviewEvent], opts TimelineOptions) iter.Seq[Snapshot] {
+	return func(yield func(Snapshot) bool) {
+		card := initial
+		for event := range events {
+			snapshot := Snapshot{Event: event}
+			if !card.LastReviewedAt.IsZero() && event.At.Sub(card.LastReviewedAt) < opts.MinGap {
+				snapshot.Skipped = true
+			} else {
+				card, snapshot.Output = e.apply(card, event, opts)
+			}
+			snapshot.State = card
+			if !yield(snapshot) {
+				return
+			}
+		}
+	}
+}
+
+// apply 對一題套用一次練習，回傳新狀態與演算法的輸出
+func (e *Engine) apply(card CardState, event ReviewEvent, opts TimelineOptions) (CardState, ReviewOutput) {
+	actualDays := 0.0
+	if !card.LastReviewedAt.IsZero() {
+		actualDays = event.At.Sub(card.LastReviewedAt).Hours() / 24.0
+	}
+
+	card.ReviewCount++
+	out := e.Review(FuzzKey{
+		UserID:      opts.UserID,
+		QuestionID:  opts.QuestionID,
+		ReviewCount: card.ReviewCount,
+	}, ReviewInput{
+		CurrentInterval: card.IntervalDays,
+		CurrentEF:       card.EaseFactor,
+		Repetitions:     card.Repetitions,
+		Grade:           event.Grade,
+		ActualDays:      actualDays,
+		Stability:       card.Stability,
+		Difficulty:      card.Difficulty,
+		ReviewedAt:      event.At,
+		Phase:           card.Phase,
+		Step:            card.Step,
+		Deadline:        opts.Deadline,
+		Explain:         opts.Explain,
+		SecondIntervals: opts.SecondIntervals,
+	})
+
+	card.IntervalDays = out.Interval
+	card.IntervalMinutes = out.IntervalMinutes
+	card.EaseFactor = out.EaseFactor
+	card.Repetitions = out.Repetitions
+	card.Stability = out.Stability
+	card.Difficulty = out.Difficulty
+	card.Phase = out.Phase
+	card.Step = out.Step
+	card.NextReviewAt = out.NextReviewAt
+	card.LastReviewedAt = event.At
+	return card, out
+}
@@ -0,0 +1,43 @@
+package srs
+
+// 排程追蹤的步驟名稱
+const (
+	TraceEF              = "ef"               // EF 更新
+	TraceBaseInterval    = "base_interval"    // 基礎間隔 (上次間隔 * EF，或前兩次的固定間隔)
+	TraceModifier        = "modifier"         // Hard 懲罰 / Easy 獎勵
+	TraceRetentionBonus  = "retention_bonus"  // 隔很久還答對的長期記憶獎勵
+	TraceLateReview      = "late_review"      // 晚做又答對，延遲算進間隔
+	TraceEarlyReview     = "early_review"     // 提早複習，成長打折
+	TraceScheduler       = "scheduler"        // 演算法算出的間隔 (Fuzz 之前)
+	TraceFuzz            = "fuzz"             // 隨機波動
+	TraceBalance         = "load_balance"     // 在 Fuzz 範圍內挑選最空的一天
+	TraceMaximumInterval = "maximum_interval" // 間隔上限
+	TraceDeadline        = "deadline"         // 面試模式的壓縮
+	TraceLearningStep    = "learning_step"    // 分鐘級的學習步驟
+)
+
+// TraceStep 排程計算中的一個步驟 (ReviewInput.Explain 為 true 時才會記錄)
+// 一般是 Output = Input * Factor，沒有倍率的步驟 Factor 為 0
+type TraceStep struct {
+	Step   string
+	Input  float64
+	Factor float64
+	Output float64
+	Note   string
+}
+
+// tracer 在 Explain 關閉時不做任何事，讓演算法不必到處判斷
+type tracer struct {
+	enabled bool
+	steps   []TraceStep
+}
+
+func newTracer(input ReviewInput) *tracer {
+	return &tracer{enabled: input.Explain}
+}
+
+func (t *tracer) add(step TraceStep) {
+	if t.enabled {
+		t.steps = append(t.steps, step)
+	}
+}